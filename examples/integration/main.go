@@ -47,6 +47,16 @@ func main() {
 		log.Fatalf("Failed to setup MCP server routes: %v", err)
 	}
 
+	// Expose existing routes as MCP tools, served in-process through the router
+	if err := mcp.ExposeRoute(ginmcp.RouteTool{
+		Method:      "GET",
+		Path:        "/api/users",
+		Name:        "list_users",
+		Description: "List all users of the application",
+	}); err != nil {
+		log.Fatalf("Failed to expose route as MCP tool: %v", err)
+	}
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"gin-mcp/registry"
+)
+
+// maxAPIResourceBytes bounds the size of fetched API resources
+const maxAPIResourceBytes = 10 << 20
+
+// apiContent is the last fetched content of an API resource
+type apiContent struct {
+	body         []byte
+	mimeType     string
+	etag         string
+	lastModified string
+	fetchedAt    time.Time
+}
+
+// apiEntry caches the content of an API resource
+type apiEntry struct {
+	registration uint64 // Registration of the resource the content was fetched for
+	content      apiContent
+	mutex        sync.Mutex // Serializes fetches of the resource
+}
+
+// apiCache holds the content of API resources by resource name
+type apiCache struct {
+	entries map[string]*apiEntry
+	mutex   sync.Mutex
+}
+
+// entry returns the cache entry of a resource. A re-registered resource starts over.
+func (c *apiCache) entry(resourceInfo *registry.ResourceInfo) *apiEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*apiEntry)
+	}
+	entry, exists := c.entries[resourceInfo.Name]
+	if !exists || entry.registration != resourceInfo.Registration {
+		entry = &apiEntry{registration: resourceInfo.Registration}
+		c.entries[resourceInfo.Name] = entry
+	}
+	return entry
+}
+
+// readAPIResource returns the content of an API resource, or the result of a
+// query against it, converted to format if one is given. It is fetched from
+// the upstream endpoint when the cached copy is stale.
+func (h *MCPHandler) readAPIResource(ctx context.Context, resourceInfo *registry.ResourceInfo, query QueryRequest, format string) ([]byte, error) {
+	content, err := h.fetchAPIResource(ctx, resourceInfo, false)
+	if err != nil {
+		return nil, err
+	}
+	if query.active() {
+		return h.queryContent(resourceInfo, content.mimeType, bytes.NewReader(content.body), query, format)
+	}
+
+	body, mimeType, err := convertContent(resourceInfo.Name, content.mimeType, content.body, format)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := h.resourceContent(resourceInfo.Name, resourceInfo.URI, mimeType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"contents": []map[string]interface{}{item},
+	}
+
+	return json.Marshal(response)
+}
+
+// RefreshResource fetches an API resource regardless of its TTL, notifying
+// subscribers when the content changed
+func (h *MCPHandler) RefreshResource(ctx context.Context, resourceInfo *registry.ResourceInfo) error {
+	if resourceInfo.API == nil {
+		return fmt.Errorf("resource %s is not an API resource", resourceInfo.Name)
+	}
+	_, err := h.fetchAPIResource(ctx, resourceInfo, true)
+	return err
+}
+
+// fetchAPIResource returns fresh content from the cache, or fetches it. Stale
+// content is revalidated with the validators of the previous response.
+func (h *MCPHandler) fetchAPIResource(ctx context.Context, resourceInfo *registry.ResourceInfo, force bool) (apiContent, error) {
+	spec := resourceInfo.API
+	entry := h.apiCache.entry(resourceInfo)
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	content := &entry.content
+
+	cached := content.body != nil
+	if cached && !force && time.Since(content.fetchedAt) < spec.Refresh.TTLDuration() {
+		return *content, nil
+	}
+
+	timeout := spec.TimeoutDuration(executionTimeout(ctx))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, spec.URL, nil)
+	if err != nil {
+		return apiContent{}, fmt.Errorf("failed to build request for resource %s: %w", resourceInfo.Name, err)
+	}
+	for name, value := range spec.Headers {
+		// Secrets may have been removed since the resource was registered
+		expanded, err := registry.ExpandSecrets(value, h.secrets.Lookup)
+		if err != nil {
+			return apiContent{}, fmt.Errorf("invalid %s header of resource %s: %w", name, resourceInfo.Name, err)
+		}
+		request.Header.Set(name, expanded)
+	}
+	if cached && spec.Refresh.Revalidates() {
+		if content.etag != "" {
+			request.Header.Set("If-None-Match", content.etag)
+		}
+		if content.lastModified != "" {
+			request.Header.Set("If-Modified-Since", content.lastModified)
+		}
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return apiContent{}, fmt.Errorf("failed to fetch resource %s: %w", resourceInfo.Name, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified && cached {
+		content.fetchedAt = time.Now()
+		return *content, nil
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return apiContent{}, fmt.Errorf("upstream of resource %s returned status %d", resourceInfo.Name, response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxAPIResourceBytes+1))
+	if err != nil {
+		return apiContent{}, fmt.Errorf("failed to read resource %s: %w", resourceInfo.Name, err)
+	}
+	if len(body) > maxAPIResourceBytes {
+		return apiContent{}, fmt.Errorf("resource %s exceeds %d bytes", resourceInfo.Name, maxAPIResourceBytes)
+	}
+
+	changed := cached && !bytes.Equal(body, content.body)
+
+	content.body = body
+	content.etag = response.Header.Get("ETag")
+	content.lastModified = response.Header.Get("Last-Modified")
+	content.fetchedAt = time.Now()
+	content.mimeType = resourceInfo.MimeType
+	if content.mimeType == "" {
+		content.mimeType = response.Header.Get("Content-Type")
+	}
+
+	if changed {
+		log.Printf("🔔 API resource %s changed", resourceInfo.Name)
+		h.notify(MethodResourceUpdated, map[string]interface{}{
+			"uri":  resourceInfo.URI,
+			"name": resourceInfo.Name,
+		})
+	}
+
+	return *content, nil
+}
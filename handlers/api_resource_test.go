@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-mcp/registry"
+)
+
+// testUpstream serves versioned content with an ETag and counts requests
+type testUpstream struct {
+	content     string
+	requests    int
+	notModified int
+	auth        string
+	mutex       sync.Mutex
+}
+
+func (u *testUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.requests++
+	u.auth = r.Header.Get("Authorization")
+
+	etag := fmt.Sprintf(`"%x"`, len(u.content))
+	if r.Header.Get("If-None-Match") == etag {
+		u.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, u.content)
+}
+
+func (u *testUpstream) counts() (int, int) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return u.requests, u.notModified
+}
+
+func TestMCPHandler_APIResource(t *testing.T) {
+	upstream := &testUpstream{content: `{"status": "green"}`}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	t.Setenv("TEST_SECRET_API_TOKEN", "t0ken")
+	t.Setenv("API_TOKEN", "not a secret")
+	secrets, err := NewSecrets("", "TEST_SECRET_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	reg := registry.NewRegistry()
+	reg.SetSecretLookup(secrets.Lookup)
+	for name, ttl := range map[string]string{"cached": "1h", "revalidated": ""} {
+		definition := fmt.Sprintf("url: %s/status\nheaders:\n  Authorization: Bearer ${API_TOKEN}\nrefresh:\n  ttl: %q\n", server.URL, ttl)
+		path := filepath.Join(dir, name+".api.yaml")
+		if err := os.WriteFile(path, []byte(definition), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.RegisterResource(registry.NameFromPath(path), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached, _ := reg.GetResource("cached")
+	revalidated, _ := reg.GetResource("revalidated")
+	if cached.Type != registry.APIRResource {
+		t.Fatalf("Expected an API resource, got %s", cached.Type)
+	}
+
+	// Headers may only reference configured secrets, not the server environment
+	for header, wantErr := range map[string]string{"${HOME}": "secret HOME is not configured", "${API_TOKEN": "unterminated"} {
+		path := filepath.Join(dir, "invalid.api.yaml")
+		definition := fmt.Sprintf("url: %s/status\nheaders:\n  Authorization: %q\n", server.URL, header)
+		if err := os.WriteFile(path, []byte(definition), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.RegisterResource("invalid", path); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("RegisterResource() with header %s error = %v, want %q", header, err, wantErr)
+		}
+	}
+
+	handler := NewMCPHandler()
+	handler.SetSecrets(secrets)
+	notifications, unsubscribe := handler.Subscribe()
+	defer unsubscribe()
+
+	read := func(resource *registry.ResourceInfo) string {
+		t.Helper()
+		result, err := handler.AccessResource(resource, []byte(`{}`))
+		if err != nil {
+			t.Fatalf("AccessResource() error = %v", err)
+		}
+		var response struct {
+			Contents []struct {
+				MimeType string `json:"mime_type"`
+				Text     string `json:"text"`
+			} `json:"contents"`
+		}
+		if err := json.Unmarshal(result, &response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Contents[0].MimeType != "application/json" {
+			t.Errorf("mime_type = %s, want application/json", response.Contents[0].MimeType)
+		}
+		return response.Contents[0].Text
+	}
+
+	// Fresh content is served from the cache
+	read(cached)
+	if text := read(cached); text != `{"status": "green"}` {
+		t.Errorf("text = %s", text)
+	}
+	if requests, _ := upstream.counts(); requests != 1 {
+		t.Errorf("Expected 1 upstream request within the TTL, got %d", requests)
+	}
+	if upstream.auth != "Bearer t0ken" {
+		t.Errorf("Authorization = %q, want the expanded token", upstream.auth)
+	}
+
+	// Reloading the metadata keeps the cached content
+	reg.ReloadResourceMetadata("cached")
+	reloaded, _ := reg.GetResource("cached")
+	read(reloaded)
+	if requests, _ := upstream.counts(); requests != 1 {
+		t.Errorf("Expected the cache to survive a metadata reload, got %d upstream requests", requests)
+	}
+
+	// Without a TTL, content is revalidated with its ETag
+	read(revalidated)
+	read(revalidated)
+	if requests, notModified := upstream.counts(); requests != 3 || notModified != 1 {
+		t.Errorf("Expected 3 requests with 1 revalidation, got %d and %d", requests, notModified)
+	}
+
+	select {
+	case notification := <-notifications:
+		t.Fatalf("Unexpected notification %v for unchanged content", notification)
+	default:
+	}
+
+	// Changed content is fetched and announced
+	upstream.mutex.Lock()
+	upstream.content = `{"status": "red, all hands"}`
+	upstream.mutex.Unlock()
+
+	if text := read(revalidated); text != `{"status": "red, all hands"}` {
+		t.Errorf("text = %s", text)
+	}
+
+	select {
+	case notification := <-notifications:
+		if notification.Method != MethodResourceUpdated || notification.Params["name"] != "revalidated" {
+			t.Errorf("Unexpected notification %v", notification)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a resource updated notification")
+	}
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gin-mcp/registry"
+)
+
+// Default limits of the tool result cache
+const (
+	DefaultCacheMaxEntries = 1000
+	DefaultCacheMaxBytes   = 64 << 20
+)
+
+// CacheStats contains result cache counters, in total or for a single tool
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// cacheEntry is a cached tool result
+type cacheEntry struct {
+	key          string
+	name         string
+	registration uint64
+	result       []byte
+	expires      time.Time
+}
+
+// resultCache is an LRU cache of tool results bounded by entry count and total size
+type resultCache struct {
+	maxEntries int
+	maxBytes   int64
+
+	entries map[string]*list.Element
+	lru     *list.List
+	bytes   int64
+	stats   map[string]*CacheStats
+	current map[string]uint64 // Registration whose results are cached, per tool
+	mutex   sync.Mutex
+}
+
+// newResultCache creates an empty result cache with the default limits
+func newResultCache() *resultCache {
+	return &resultCache{
+		maxEntries: DefaultCacheMaxEntries,
+		maxBytes:   DefaultCacheMaxBytes,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		stats:      make(map[string]*CacheStats),
+		current:    make(map[string]uint64),
+	}
+}
+
+// SetCacheLimits bounds the result cache. Values below 1 restore the defaults.
+func (h *MCPHandler) SetCacheLimits(maxEntries int, maxBytes int64) {
+	if maxEntries < 1 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	if maxBytes < 1 {
+		maxBytes = DefaultCacheMaxBytes
+	}
+
+	h.cache.mutex.Lock()
+	defer h.cache.mutex.Unlock()
+	h.cache.maxEntries = maxEntries
+	h.cache.maxBytes = maxBytes
+	h.cache.evict()
+}
+
+// CacheStats returns the result cache counters per tool and in total
+func (h *MCPHandler) CacheStats() (CacheStats, map[string]CacheStats) {
+	h.cache.mutex.Lock()
+	defer h.cache.mutex.Unlock()
+
+	total := CacheStats{Entries: h.cache.lru.Len(), Bytes: h.cache.bytes}
+	perTool := make(map[string]CacheStats, len(h.cache.stats))
+	for name, stats := range h.cache.stats {
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Evictions += stats.Evictions
+		perTool[name] = *stats
+	}
+
+	for element := h.cache.lru.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*cacheEntry)
+		stats := perTool[entry.name]
+		stats.Entries++
+		stats.Bytes += int64(len(entry.result))
+		perTool[entry.name] = stats
+	}
+
+	return total, perTool
+}
+
+// executeCached returns a cached result for tools that opt into caching through
+// their manifest, executing the tool on a miss. Only successful results are cached.
+func (h *MCPHandler) executeCached(toolInfo *registry.ToolInfo, input []byte, execute func() ([]byte, error)) ([]byte, error) {
+	var ttl time.Duration
+	if toolInfo.Manifest != nil {
+		ttl = toolInfo.Manifest.Cache.TTLDuration()
+	}
+	if ttl <= 0 {
+		return execute()
+	}
+
+	key, err := cacheKey(toolInfo, input)
+	if err != nil {
+		return execute()
+	}
+
+	if result, found := h.cache.get(key, toolInfo); found {
+		return result, nil
+	}
+
+	result, err := execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var outcome struct {
+		IsError bool `json:"isError"`
+	}
+	if json.Unmarshal(result, &outcome) == nil && !outcome.IsError {
+		h.cache.put(key, toolInfo, result, ttl)
+	}
+	return result, nil
+}
+
+// cacheKey derives the cache key from the tool name, the tool file hash and the
+// canonicalized arguments
+func cacheKey(toolInfo *registry.ToolInfo, input []byte) (string, error) {
+	arguments, err := parseArguments(input)
+	if err != nil {
+		return "", err
+	}
+
+	// Marshalling decoded JSON sorts object keys, which canonicalizes the arguments
+	canonical, err := json.Marshal(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(toolInfo.Name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(toolInfo.FileHash))
+	hash.Write([]byte{0})
+	hash.Write(canonical)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// get looks up a result. When the tool was re-registered since its results were
+// cached, all of them are dropped.
+func (c *resultCache) get(key string, toolInfo *registry.ToolInfo) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.statsFor(toolInfo.Name)
+
+	if c.current[toolInfo.Name] != toolInfo.Registration {
+		c.invalidate(toolInfo.Name, toolInfo.Registration)
+		c.current[toolInfo.Name] = toolInfo.Registration
+	}
+
+	if element, exists := c.entries[key]; exists {
+		entry := element.Value.(*cacheEntry)
+		if time.Now().After(entry.expires) {
+			c.remove(element)
+		} else {
+			c.lru.MoveToFront(element)
+			stats.Hits++
+			return entry.result, true
+		}
+	}
+
+	stats.Misses++
+	return nil, false
+}
+
+// put stores a result and evicts the least recently used entries beyond the limits
+func (c *resultCache) put(key string, toolInfo *registry.ToolInfo, result []byte, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Skip results that are too large or belong to a registration replaced meanwhile
+	if int64(len(result)) > c.maxBytes || c.current[toolInfo.Name] != toolInfo.Registration {
+		return
+	}
+
+	if element, exists := c.entries[key]; exists {
+		c.remove(element)
+	}
+
+	entry := &cacheEntry{key: key, name: toolInfo.Name, registration: toolInfo.Registration, result: result, expires: time.Now().Add(ttl)}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.bytes += int64(len(result))
+	c.evict()
+}
+
+// invalidate drops cached results of a tool that belong to a registration other than current
+func (c *resultCache) invalidate(name string, current uint64) {
+	for element := c.lru.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*cacheEntry); entry.name == name && entry.registration != current {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// evict removes least recently used entries until the cache is within its limits
+func (c *resultCache) evict() {
+	for c.lru.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		element := c.lru.Back()
+		c.statsFor(element.Value.(*cacheEntry).name).Evictions++
+		c.remove(element)
+	}
+}
+
+// remove deletes an entry. The caller must hold the mutex.
+func (c *resultCache) remove(element *list.Element) {
+	entry := c.lru.Remove(element).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= int64(len(entry.result))
+}
+
+// statsFor returns the counters of a tool. The caller must hold the mutex.
+func (c *resultCache) statsFor(name string) *CacheStats {
+	stats, exists := c.stats[name]
+	if !exists {
+		stats = &CacheStats{}
+		c.stats[name] = stats
+	}
+	return stats
+}
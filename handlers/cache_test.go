@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_ResultCache(t *testing.T) {
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"sum": "%s+%s"}`, r.URL.Query().Get("a"), r.URL.Query().Get("b"))
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	toolPath := filepath.Join(dir, "add.http.yaml")
+	writeTool := func(description string) {
+		definition := "description: " + description + "\nurl: " + upstream.URL + "/add\n"
+		if err := os.WriteFile(toolPath, []byte(definition), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTool("Add numbers")
+	if err := os.WriteFile(filepath.Join(dir, "add.manifest.yaml"), []byte("cache:\n  ttl: 1m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := registry.NewRegistry()
+	if err := reg.RegisterTool("add", toolPath, "add"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewMCPHandler()
+	execute := func(input string) {
+		tool, _ := reg.GetTool("add")
+		if _, err := handler.ExecuteTool(tool, []byte(input)); err != nil {
+			t.Fatalf("ExecuteTool() error = %v", err)
+		}
+	}
+
+	steps := []struct {
+		name     string
+		input    string
+		requests int32
+	}{
+		{"first call misses", `{"arguments": {"a": 1, "b": 2}}`, 1},
+		{"reordered arguments hit", `{"arguments": {"b": 2, "a": 1.0}}`, 1},
+		{"different arguments miss", `{"arguments": {"a": 2, "b": 2}}`, 2},
+	}
+	for _, step := range steps {
+		execute(step.input)
+		if got := atomic.LoadInt32(&requests); got != step.requests {
+			t.Errorf("%s: upstream requests = %d, want %d", step.name, got, step.requests)
+		}
+	}
+
+	total, perTool := handler.CacheStats()
+	if total.Hits != 1 || total.Misses != 2 || perTool["add"].Entries != 2 {
+		t.Errorf("Unexpected cache stats: %+v %+v", total, perTool)
+	}
+
+	// Reloading the manifest keeps the cached results
+	if err := reg.ReloadManifest("add"); err != nil {
+		t.Fatal(err)
+	}
+	execute(`{"arguments": {"a": 1, "b": 2}}`)
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("Expected a hit after a manifest reload, upstream requests = %d", got)
+	}
+
+	// Re-registering a changed tool invalidates its cached results
+	writeTool("Add two numbers")
+	if err := reg.RegisterTool("add", toolPath, "add"); err != nil {
+		t.Fatal(err)
+	}
+	execute(`{"arguments": {"a": 1, "b": 2}}`)
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected a miss after re-registration, upstream requests = %d", got)
+	}
+	if total, _ := handler.CacheStats(); total.Entries != 1 {
+		t.Errorf("Expected stale entries to be dropped, got %d entries", total.Entries)
+	}
+
+	// Entries beyond the limit are evicted least recently used first
+	handler.SetCacheLimits(1, 0)
+	execute(`{"arguments": {"a": 5, "b": 5}}`)
+	if total, _ := handler.CacheStats(); total.Entries != 1 || total.Evictions != 1 {
+		t.Errorf("Expected eviction, got %+v", total)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"gin-mcp/registry"
+)
+
+// DefaultMaxInlineSize bounds the size of resource contents returned inline
+const DefaultMaxInlineSize = 10 << 20
+
+// ErrResourceTooLarge is returned, wrapped, for resources exceeding the maximum inline size
+var ErrResourceTooLarge = errors.New("resource too large")
+
+// SetMaxInlineSize bounds the size of resource contents returned inline. Values
+// below 1 restore the default.
+func (h *MCPHandler) SetMaxInlineSize(maxBytes int64) {
+	if maxBytes < 1 {
+		maxBytes = DefaultMaxInlineSize
+	}
+	h.maxInlineSize = maxBytes
+}
+
+// resourceContent builds an MCP resource content item. Text goes in "text",
+// anything else base64 encoded in "blob". Without a specific MIME type the
+// content is sniffed.
+func (h *MCPHandler) resourceContent(name, uri, mimeType string, data []byte) (map[string]interface{}, error) {
+	if int64(len(data)) > h.maxInlineSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, the maximum inline size is %d bytes", ErrResourceTooLarge, name, len(data), h.maxInlineSize)
+	}
+
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	content := map[string]interface{}{
+		"uri":       uri,
+		"mime_type": mimeType,
+	}
+	if registry.IsTextMimeType(mimeType) && utf8.Valid(data) {
+		content["text"] = string(data)
+	} else {
+		content["blob"] = base64.StdEncoding.EncodeToString(data)
+	}
+	return content, nil
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_ResourceContent(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+
+	tests := []struct {
+		name     string
+		file     string
+		data     []byte
+		wantMime string
+		wantBlob bool
+		wantErr  error
+	}{
+		{"json is text", "data.json", []byte(`{"ok": true}`), "application/json", false, nil},
+		{"png is a blob", "logo.png", png, "image/png", true, nil},
+		{"unknown text is sniffed", "notes.log42", []byte("plain notes\n"), "text/plain; charset=utf-8", false, nil},
+		{"unknown binary is sniffed", "archive.bin42", []byte("%PDF-1.7\n\x00\x01"), "application/pdf", true, nil},
+		{"invalid utf-8 text is a blob", "broken.txt", []byte("caf\xe9"), "text/plain", true, nil},
+		{"oversized", "large.txt", make([]byte, 65), "", false, ErrResourceTooLarge},
+	}
+
+	dir := t.TempDir()
+	reg := registry.NewRegistry()
+	handler := NewMCPHandler()
+	handler.SetMaxInlineSize(64)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := reg.RegisterResource(registry.NameFromPath(path), path); err != nil {
+				t.Fatal(err)
+			}
+			resource, _ := reg.GetResource(registry.NameFromPath(path))
+
+			result, err := handler.AccessResource(resource, []byte(`{}`))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AccessResource() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AccessResource() error = %v", err)
+			}
+
+			var response struct {
+				Contents []map[string]string `json:"contents"`
+			}
+			if err := json.Unmarshal(result, &response); err != nil {
+				t.Fatal(err)
+			}
+			content := response.Contents[0]
+
+			if content["mime_type"] != tt.wantMime {
+				t.Errorf("mime_type = %q, want %q", content["mime_type"], tt.wantMime)
+			}
+			if tt.wantBlob {
+				decoded, err := base64.StdEncoding.DecodeString(content["blob"])
+				if err != nil || string(decoded) != string(tt.data) {
+					t.Errorf("blob = %q does not decode to the file content", content["blob"])
+				}
+				if _, exists := content["text"]; exists {
+					t.Error("Expected no text for a blob")
+				}
+			} else if content["text"] != string(tt.data) {
+				t.Errorf("text = %q, want %q", content["text"], tt.data)
+			}
+		})
+	}
+}
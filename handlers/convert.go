@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedFormat is returned, wrapped, when a resource cannot be converted to the requested format
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Content formats that resources are converted between
+const (
+	formatJSON     = "json"
+	formatYAML     = "yaml"
+	formatCSV      = "csv"
+	formatXML      = "xml"
+	formatMarkdown = "markdown"
+	formatText     = "text"
+)
+
+// formatMimeTypes are the MIME types of converted content
+var formatMimeTypes = map[string]string{
+	formatJSON: "application/json",
+	formatYAML: "application/x-yaml",
+	formatCSV:  "text/csv",
+	formatText: "text/plain",
+}
+
+// converters convert content from a source format to a target format
+var converters = map[[2]string]func([]byte) ([]byte, error){
+	{formatCSV, formatJSON}:      csvToJSON,
+	{formatJSON, formatCSV}:      jsonToCSV,
+	{formatYAML, formatJSON}:     yamlToJSON,
+	{formatJSON, formatYAML}:     jsonToYAML,
+	{formatXML, formatJSON}:      xmlToJSON,
+	{formatMarkdown, formatText}: markdownToText,
+}
+
+// FormatRequest asks for the content of a resource in another format in the
+// body of a resource read: json, yaml, csv or text
+type FormatRequest struct {
+	Format string `json:"format,omitempty"`
+}
+
+// parseFormatRequest extracts the requested output format from the body of a resource read
+func parseFormatRequest(input []byte) (FormatRequest, error) {
+	var request FormatRequest
+	if err := json.Unmarshal(input, &request); err != nil {
+		return request, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	request.Format = strings.ToLower(request.Format)
+	return request, nil
+}
+
+// contentFormat returns the format of a MIME type, or "" if it is not one that is converted
+func contentFormat(mimeType string) string {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = mimeType
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return formatJSON
+	case mediaType == "application/x-yaml" || mediaType == "application/yaml" || mediaType == "text/yaml":
+		return formatYAML
+	case mediaType == "text/csv":
+		return formatCSV
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return formatXML
+	case mediaType == "text/markdown":
+		return formatMarkdown
+	case mediaType == "text/plain":
+		return formatText
+	}
+	return ""
+}
+
+// convertContent converts resource content to the requested format and returns
+// it with its new MIME type. Content already in that format is returned as is.
+func convertContent(name, mimeType string, data []byte, format string) ([]byte, string, error) {
+	source := contentFormat(mimeType)
+	if format == "" || format == source {
+		return data, mimeType, nil
+	}
+
+	convert, exists := converters[[2]string{source, format}]
+	if !exists {
+		if source == "" {
+			source = mimeType
+		}
+		return nil, "", fmt.Errorf("%w: resource %s cannot be converted from %s to %s", ErrUnsupportedFormat, name, source, format)
+	}
+
+	converted, err := convert(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert resource %s to %s: %w", name, format, err)
+	}
+	return converted, formatMimeTypes[format], nil
+}
+
+// csvToJSON converts CSV with a header row to a JSON array of row objects
+func csvToJSON(data []byte) ([]byte, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []byte("[]"), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	output.WriteByte('[')
+	for rows := 0; ; rows++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Encode rows field by field to keep the column order of the header
+		if rows > 0 {
+			output.WriteByte(',')
+		}
+		output.WriteByte('{')
+		for i, column := range header {
+			value := ""
+			if i < len(record) {
+				value = record[i]
+			}
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			key, _ := json.Marshal(column)
+			cell, _ := json.Marshal(value)
+			output.Write(key)
+			output.WriteByte(':')
+			output.Write(cell)
+		}
+		output.WriteByte('}')
+	}
+	output.WriteByte(']')
+
+	return output.Bytes(), nil
+}
+
+// jsonToCSV converts a JSON array of objects to CSV. The header holds the keys
+// of all objects in order of appearance, nested values are written as JSON.
+func jsonToCSV(data []byte) ([]byte, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("only arrays of objects convert to csv: %w", err)
+	}
+
+	var header []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		keys, row, err := decodeObject(item)
+		if err != nil {
+			return nil, fmt.Errorf("only arrays of objects convert to csv: item %d: %w", i, err)
+		}
+		rows[i] = row
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				header = append(header, key)
+			}
+		}
+	}
+
+	var output bytes.Buffer
+	writer := csv.NewWriter(&output)
+	writer.Write(header)
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, key := range header {
+			record[i] = csvCell(row[key])
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+
+	return output.Bytes(), writer.Error()
+}
+
+// decodeObject decodes a JSON object, returning its keys in document order
+func decodeObject(data json.RawMessage) ([]string, map[string]json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, errors.New("not an object")
+	}
+
+	var keys []string
+	object := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, exists := object[key]; !exists {
+			keys = append(keys, key)
+		}
+		object[key] = value
+	}
+	return keys, object, nil
+}
+
+// csvCell formats a JSON value as a CSV cell: strings unquoted, null empty and
+// everything else as JSON
+func csvCell(value json.RawMessage) string {
+	if len(value) == 0 || string(value) == "null" {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return text
+	}
+	return string(value)
+}
+
+// yamlToJSON converts a YAML document to JSON, keeping the key order
+func yamlToJSON(data []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	if err := writeYAMLNode(&output, &document); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// writeYAMLNode writes a YAML node as JSON
+func writeYAMLNode(output *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			output.WriteString("null")
+			return nil
+		}
+		return writeYAMLNode(output, node.Content[0])
+	case yaml.AliasNode:
+		return writeYAMLNode(output, node.Alias)
+	case yaml.MappingNode:
+		output.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			output.Write(key)
+			output.WriteByte(':')
+			if err := writeYAMLNode(output, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		output.WriteByte('}')
+	case yaml.SequenceNode:
+		output.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			if err := writeYAMLNode(output, child); err != nil {
+				return err
+			}
+		}
+		output.WriteByte(']')
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		output.Write(encoded)
+	}
+	return nil
+}
+
+// jsonToYAML converts JSON to YAML in block style, keeping the key order
+func jsonToYAML(data []byte) ([]byte, error) {
+	// JSON is YAML, decoding it into nodes keeps the order of keys
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("invalid JSON")
+	}
+	resetStyle(&document)
+	return yaml.Marshal(&document)
+}
+
+// resetStyle clears the flow and quoting styles taken over from JSON
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// xmlToJSON converts an XML document to JSON. Elements become objects keyed by
+// child element name, repeated children become arrays, attributes are prefixed
+// with "@" and text next to attributes or children is stored under "#text".
+// Elements holding only text become strings.
+func xmlToJSON(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("no root element")
+			}
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := xmlElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(map[string]interface{}{start.Name.Local: value})
+		}
+	}
+}
+
+// xmlElement decodes the content of an element up to its end
+func xmlElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	object := make(map[string]interface{})
+	for _, attribute := range start.Attr {
+		object["@"+attribute.Name.Local] = attribute.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := xmlElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := object[name].(type) {
+			case nil:
+				object[name] = child
+			case []interface{}:
+				object[name] = append(existing, child)
+			default:
+				object[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(object) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				object["#text"] = content
+			}
+			return object, nil
+		}
+	}
+}
+
+// Markdown syntax removed when converting to plain text
+var (
+	markdownFence     = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownHeading   = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	markdownQuote     = regexp.MustCompile(`^\s*(>\s?)+`)
+	markdownRule      = regexp.MustCompile(`^\s{0,3}([-*_]\s*){3,}$`)
+	markdownUnderline = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	markdownImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownReference = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+.*$`)
+	markdownCode      = regexp.MustCompile("`([^`]*)`")
+	markdownHTML      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+	// Underscores only delimit emphasis at word boundaries, not inside snake_case
+	markdownEmphasis = []*regexp.Regexp{
+		regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`),
+		regexp.MustCompile(`\b__(\S(?:.*?\S)?)__\b`),
+		regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`),
+		regexp.MustCompile(`\b_(\S(?:.*?\S)?)_\b`),
+		regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`),
+	}
+)
+
+// markdownToText strips Markdown syntax, keeping the text, the content of code
+// blocks and list markers
+func markdownToText(data []byte) ([]byte, error) {
+	var output strings.Builder
+	inFence := false
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if markdownFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			if markdownRule.MatchString(line) || markdownUnderline.MatchString(line) || markdownReference.MatchString(line) {
+				continue
+			}
+			line = markdownHeading.ReplaceAllString(line, "")
+			line = markdownQuote.ReplaceAllString(line, "")
+			line = markdownImage.ReplaceAllString(line, "$1")
+			line = markdownLink.ReplaceAllString(line, "$1")
+			line = markdownCode.ReplaceAllString(line, "$1")
+			for _, emphasis := range markdownEmphasis {
+				line = emphasis.ReplaceAllString(line, "$1")
+			}
+			line = markdownHTML.ReplaceAllString(line, "")
+		}
+		output.WriteString(line)
+		output.WriteByte('\n')
+	}
+
+	return []byte(strings.TrimSpace(output.String()) + "\n"), nil
+}
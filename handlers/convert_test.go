@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_ConvertResource(t *testing.T) {
+	files := map[string]string{
+		"sales.csv":  "region,total\neu,120\nus,\"8,5\"\n",
+		"rows.json":  `[{"name": "pen", "price": 2}, {"name": "lamp", "tags": ["desk"], "price": null}]`,
+		"config.yml": "server:\n  port: 8080\n  hosts: [b, a]\nname: demo\n",
+		"feed.xml":   `<feed lang="en"><title>News</title><entry id="1">First</entry><entry id="2">Second</entry></feed>`,
+		"readme.md":  "# Title\n\nSome **bold** and _em_ text with a [link](https://example.com) and `code`, keep snake_case_names.\n\n```\nraw *code*\n```\n\n> quoted\n\n---\n- item\n",
+	}
+
+	dir := t.TempDir()
+	reg := registry.NewRegistry()
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.RegisterResource(registry.NameFromPath(path), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := NewMCPHandler()
+
+	tests := []struct {
+		name     string
+		resource string
+		body     string
+		wantMime string
+		wantText string
+		wantErr  error
+	}{
+		{"csv to json", "sales", `{"format": "json"}`, "application/json", `[{"region":"eu","total":"120"},{"region":"us","total":"8,5"}]`, nil},
+		{"json to csv", "rows", `{"format": "csv"}`, "text/csv", "name,price,tags\npen,2,\nlamp,,\"[\"\"desk\"\"]\"\n", nil},
+		{"yaml to json", "config", `{"format": "json"}`, "application/json", `{"server":{"port":8080,"hosts":["b","a"]},"name":"demo"}`, nil},
+		{"json to yaml", "rows", `{"format": "yaml", "query": "$[0]"}`, "application/x-yaml", "name: pen\nprice: 2\n", nil},
+		{"xml to json", "feed", `{"format": "json"}`, "application/json", `{"feed":{"@lang":"en","entry":[{"#text":"First","@id":"1"},{"#text":"Second","@id":"2"}],"title":"News"}}`, nil},
+		{"markdown to text", "readme", `{"format": "text"}`, "text/plain", "Title\n\nSome bold and em text with a link and code, keep snake_case_names.\n\nraw *code*\n\nquoted\n\n- item\n", nil},
+		{"query then convert", "sales", `{"filter": "@.total > 100", "format": "json"}`, "application/json", `[{"region":"eu","total":"120"}]`, nil},
+		{"same format", "sales", `{"format": "csv"}`, "text/csv", files["sales.csv"], nil},
+		{"unsupported conversion", "readme", `{"format": "csv"}`, "", "", ErrUnsupportedFormat},
+		{"conversion of a partial read", "sales", `{"format": "json", "bytes": {}}`, "", "", ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, _ := reg.GetResource(tt.resource)
+			result, err := handler.AccessResource(resource, []byte(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AccessResource() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AccessResource() error = %v", err)
+			}
+
+			var response struct {
+				Contents []struct {
+					MimeType string `json:"mime_type"`
+					Text     string `json:"text"`
+				} `json:"contents"`
+			}
+			if err := json.Unmarshal(result, &response); err != nil {
+				t.Fatal(err)
+			}
+			content := response.Contents[0]
+			if content.MimeType != tt.wantMime {
+				t.Errorf("mime_type = %s, want %s", content.MimeType, tt.wantMime)
+			}
+			if content.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", content.Text, tt.wantText)
+			}
+		})
+	}
+}
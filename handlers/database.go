@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"gin-mcp/registry"
+)
+
+// DefaultDatabaseMaxRows bounds the rows returned by a SQL resource
+const DefaultDatabaseMaxRows = 1000
+
+// Placeholder styles of database drivers
+const (
+	PlaceholderQuestion = "?"  // SQLite, MySQL
+	PlaceholderDollar   = "$"  // PostgreSQL
+	PlaceholderAtP      = "@p" // SQL Server
+)
+
+// DatabaseSettings control how .sql resources run against the database
+type DatabaseSettings struct {
+	MaxRows     int           // Maximum rows per query (default: 1000)
+	Timeout     time.Duration // Query timeout (default: DefaultTimeout)
+	AllowWrites bool          // Permit statements other than queries
+	Placeholder string        // Placeholder style of the driver (default: "?")
+}
+
+// database is the configured database of SQL resources
+type database struct {
+	db       *sql.DB
+	settings DatabaseSettings
+
+	noReadOnlyTx atomic.Bool // The driver rejects read-only transactions, such as SQL Server's
+}
+
+// writeKeywords are statement keywords rejected for read-only databases
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true, "MERGE": true, "UPSERT": true,
+	"CREATE": true, "DROP": true, "ALTER": true, "TRUNCATE": true, "RENAME": true,
+	"GRANT": true, "REVOKE": true, "ATTACH": true, "DETACH": true, "VACUUM": true, "REINDEX": true,
+	"PRAGMA": true, "COPY": true, "CALL": true, "EXEC": true, "EXECUTE": true, "LOCK": true, "SET": true,
+}
+
+// readKeywords are the statements allowed for read-only databases
+var readKeywords = map[string]bool{"SELECT": true, "WITH": true, "VALUES": true, "EXPLAIN": true, "SHOW": true}
+
+// SetDatabase configures the database that .sql resources are executed against.
+// Without a database, .sql resources return their source text.
+func (h *MCPHandler) SetDatabase(db *sql.DB, settings DatabaseSettings) {
+	if settings.MaxRows <= 0 {
+		settings.MaxRows = DefaultDatabaseMaxRows
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = DefaultTimeout
+	}
+	if settings.Placeholder == "" {
+		settings.Placeholder = PlaceholderQuestion
+	}
+
+	h.database = &database{db: db, settings: settings}
+}
+
+// queryResource runs the statement of a .sql resource with the named
+// parameters of the request and returns the rows as JSON or CSV
+func (h *MCPHandler) queryResource(ctx context.Context, resourceInfo *registry.ResourceInfo, input []byte) ([]byte, error) {
+	var request struct {
+		Params  map[string]interface{} `json:"params"`
+		Format  string                 `json:"format"`
+		MaxRows int                    `json:"max_rows"`
+	}
+	if err := json.Unmarshal(input, &request); err != nil {
+		return nil, fmt.Errorf("invalid query request: %w", err)
+	}
+
+	settings := h.database.settings
+	maxRows := settings.MaxRows
+	if request.MaxRows > 0 && request.MaxRows < maxRows {
+		maxRows = request.MaxRows
+	}
+
+	source, err := os.ReadFile(resourceInfo.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+
+	statement, args, err := bindParameters(string(source), request.Params, settings.Placeholder)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.AllowWrites {
+		if err := checkReadOnly(statement); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settings.Timeout)
+	defer cancel()
+
+	tx, err := h.database.begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start query: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	var records [][]interface{}
+	truncated := false
+	for rows.Next() {
+		if len(records) == maxRows {
+			truncated = true
+			break
+		}
+
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		for i, value := range values {
+			// Text columns may be returned as bytes
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		records = append(records, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	if settings.AllowWrites {
+		rows.Close()
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit: %w", err)
+		}
+	}
+
+	var text, mimeType string
+	switch request.Format {
+	case "", "json":
+		text, err = formatRowsJSON(columns, records, truncated)
+		mimeType = "application/json"
+	case "csv":
+		text, err = formatRowsCSV(columns, records)
+		mimeType = "text/csv"
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected json or csv", request.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]interface{}{
+		"uri":       resourceInfo.URI,
+		"mime_type": mimeType,
+		"text":      text,
+		"row_count": len(records),
+		"truncated": truncated,
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"contents": []map[string]interface{}{content},
+	})
+}
+
+// bindParameters replaces :name parameters outside of literals and comments with
+// driver placeholders and returns the matching arguments
+func bindParameters(statement string, params map[string]interface{}, placeholder string) (string, []interface{}, error) {
+	var out strings.Builder
+	var args []interface{}
+
+	for i := 0; i < len(statement); {
+		if skip := skipLiteral(statement, i); skip > i {
+			out.WriteString(statement[i:skip])
+			i = skip
+			continue
+		}
+
+		c := statement[i]
+		// "::" is a PostgreSQL cast, not a parameter
+		if c == ':' && i+1 < len(statement) && isIdentifierStart(statement[i+1]) && (i == 0 || statement[i-1] != ':') {
+			end := i + 1
+			for end < len(statement) && isIdentifierPart(statement[end]) {
+				end++
+			}
+			name := statement[i+1 : end]
+
+			value, exists := params[name]
+			if !exists {
+				return "", nil, fmt.Errorf("missing query parameter %s", name)
+			}
+			args = append(args, value)
+
+			switch placeholder {
+			case PlaceholderDollar, PlaceholderAtP:
+				out.WriteString(placeholder + strconv.Itoa(len(args)))
+			default:
+				out.WriteString(placeholder)
+			}
+			i = end
+			continue
+		}
+
+		out.WriteByte(c)
+		i++
+	}
+
+	return out.String(), args, nil
+}
+
+// begin starts the transaction of a query. Queries of read-only databases
+// never commit, and run in a read-only transaction where the driver supports
+// one. Other drivers rely on checkReadOnly alone.
+func (d *database) begin(ctx context.Context) (*sql.Tx, error) {
+	if d.settings.AllowWrites || d.noReadOnlyTx.Load() {
+		return d.db.BeginTx(ctx, nil)
+	}
+
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err == nil {
+		return tx, nil
+	}
+
+	// A plain transaction that starts where the read-only one failed shows that
+	// the driver does not support them, rather than the database being down
+	tx, plainErr := d.db.BeginTx(ctx, nil)
+	if plainErr != nil {
+		return nil, err
+	}
+	if d.noReadOnlyTx.CompareAndSwap(false, true) {
+		log.Printf("⚠️  Database driver does not support read-only transactions (%v), relying on the statement check", err)
+	}
+	return tx, nil
+}
+
+// checkReadOnly rejects anything but a single query
+func checkReadOnly(statement string) error {
+	var tokens []string
+	for i := 0; i < len(statement); {
+		if skip := skipLiteral(statement, i); skip > i {
+			i = skip
+			continue
+		}
+		if statement[i] == ';' {
+			tokens = append(tokens, ";")
+			i++
+			continue
+		}
+		if isIdentifierStart(statement[i]) {
+			end := i
+			for end < len(statement) && isIdentifierPart(statement[end]) {
+				end++
+			}
+			// Function calls such as REPLACE(...) are not statements
+			token := strings.ToUpper(statement[i:end])
+			if !writeKeywords[token] || !strings.HasPrefix(strings.TrimLeft(statement[end:], " \t\r\n"), "(") {
+				tokens = append(tokens, token)
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+
+	// A trailing semicolon is fine, a second statement is not
+	for len(tokens) > 0 && tokens[len(tokens)-1] == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 || !readKeywords[tokens[0]] {
+		return fmt.Errorf("only queries are allowed on a read-only database")
+	}
+	for _, token := range tokens {
+		if token == ";" {
+			return fmt.Errorf("only a single statement is allowed on a read-only database")
+		}
+		if writeKeywords[token] {
+			return fmt.Errorf("%s statements are not allowed on a read-only database", token)
+		}
+		// SELECT ... INTO creates a table or writes a file
+		if token == "INTO" {
+			return fmt.Errorf("SELECT ... INTO is not allowed on a read-only database")
+		}
+	}
+	return nil
+}
+
+// skipLiteral returns the end of the string literal, quoted identifier or
+// comment starting at i, or i if there is none
+func skipLiteral(statement string, i int) int {
+	switch {
+	case statement[i] == '\'' || statement[i] == '"' || statement[i] == '`':
+		quote := statement[i]
+		for j := i + 1; j < len(statement); j++ {
+			if statement[j] == quote {
+				// A doubled quote escapes itself
+				if j+1 < len(statement) && statement[j+1] == quote {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(statement)
+	case strings.HasPrefix(statement[i:], "--"):
+		if end := strings.IndexByte(statement[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(statement)
+	case strings.HasPrefix(statement[i:], "/*"):
+		if end := strings.Index(statement[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(statement)
+	}
+	return i
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || unicode.IsLetter(rune(c))
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || unicode.IsDigit(rune(c))
+}
+
+// formatRowsJSON encodes rows as an array of objects keyed by column
+func formatRowsJSON(columns []string, records [][]interface{}, truncated bool) (string, error) {
+	rows := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		row := make(map[string]interface{}, len(columns))
+		for j, column := range columns {
+			row[column] = record[j]
+		}
+		rows[i] = row
+	}
+
+	text, err := json.Marshal(map[string]interface{}{
+		"columns":   columns,
+		"rows":      rows,
+		"truncated": truncated,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode rows: %w", err)
+	}
+	return string(text), nil
+}
+
+// formatRowsCSV encodes rows as CSV with a header line
+func formatRowsCSV(columns []string, records [][]interface{}) (string, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write(columns)
+	for _, record := range records {
+		line := make([]string, len(record))
+		for i, value := range record {
+			switch v := value.(type) {
+			case nil:
+				line[i] = ""
+			case time.Time:
+				line[i] = v.Format(time.RFC3339Nano)
+			default:
+				line[i] = fmt.Sprint(v)
+			}
+		}
+		writer.Write(line)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to encode rows: %w", err)
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gin-mcp/registry"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestMCPHandler_QueryResource(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	setup := `CREATE TABLE users (id INTEGER, name TEXT, region TEXT);
+		INSERT INTO users VALUES (1, 'Ada', 'eu'), (2, 'Grace', 'us'), (3, 'Linus', 'eu'), (4, 'Ken', 'eu')`
+	if _, err := db.Exec(setup); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewMCPHandler()
+	handler.SetDatabase(db, DatabaseSettings{MaxRows: 10})
+
+	tests := []struct {
+		name      string
+		query     string
+		input     string
+		wantMime  string
+		wantText  string
+		wantRows  int
+		wantError string
+	}{
+		{
+			name:     "named parameters",
+			query:    "-- users of a region\nSELECT id, name FROM users WHERE region = :region AND name != ':region' ORDER BY id",
+			input:    `{"params": {"region": "eu"}}`,
+			wantMime: "application/json",
+			wantText: `"rows":[{"id":1,"name":"Ada"},{"id":3,"name":"Linus"},{"id":4,"name":"Ken"}]`,
+			wantRows: 3,
+		},
+		{
+			name:     "csv",
+			query:    "SELECT id, REPLACE(name, 'a', '4') AS name FROM users WHERE id <= :max ORDER BY id",
+			input:    `{"params": {"max": 2}, "format": "csv"}`,
+			wantMime: "text/csv",
+			wantText: "id,name\n1,Ad4\n2,Gr4ce\n",
+			wantRows: 2,
+		},
+		{
+			name:     "row limit",
+			query:    "SELECT id FROM users ORDER BY id;",
+			input:    `{"max_rows": 2}`,
+			wantMime: "application/json",
+			wantText: `"truncated":true`,
+			wantRows: 2,
+		},
+		{
+			name:      "missing parameter",
+			query:     "SELECT * FROM users WHERE region = :region",
+			input:     `{}`,
+			wantError: "missing query parameter region",
+		},
+		{
+			name:      "write statement",
+			query:     "DELETE FROM users",
+			input:     `{}`,
+			wantError: "only queries are allowed",
+		},
+		{
+			name:      "hidden write statement",
+			query:     "SELECT 1; DROP TABLE users",
+			input:     `{}`,
+			wantError: "single statement",
+		},
+		{
+			name:      "select into",
+			query:     "SELECT * INTO users_copy FROM users",
+			input:     `{}`,
+			wantError: "SELECT ... INTO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryPath := filepath.Join(dir, "query.sql")
+			if err := os.WriteFile(queryPath, []byte(tt.query), 0644); err != nil {
+				t.Fatal(err)
+			}
+			resource := &registry.ResourceInfo{Name: "query", FilePath: queryPath, Type: registry.DatabaseResource}
+
+			result, err := handler.AccessResource(resource, []byte(tt.input))
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("AccessResource() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AccessResource() error = %v", err)
+			}
+
+			var response struct {
+				Contents []struct {
+					MimeType string `json:"mime_type"`
+					Text     string `json:"text"`
+					RowCount int    `json:"row_count"`
+				} `json:"contents"`
+			}
+			if err := json.Unmarshal(result, &response); err != nil {
+				t.Fatal(err)
+			}
+			content := response.Contents[0]
+			if content.MimeType != tt.wantMime || content.RowCount != tt.wantRows {
+				t.Errorf("Got %s with %d rows, want %s with %d rows", content.MimeType, content.RowCount, tt.wantMime, tt.wantRows)
+			}
+			if !strings.Contains(content.Text, tt.wantText) {
+				t.Errorf("Text = %s, want it to contain %s", content.Text, tt.wantText)
+			}
+		})
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 4 {
+		t.Errorf("Expected the table to be unchanged, got %d rows (%v)", count, err)
+	}
+}
+
+func TestMCPHandler_QueryResourceWithoutReadOnlyTransactions(t *testing.T) {
+	dir := t.TempDir()
+
+	sqlite, err := sql.Open("sqlite", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+	if _, err := sqlite.Exec("CREATE TABLE users (id INTEGER, name TEXT); INSERT INTO users VALUES (1, 'Ada')"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Like SQL Server's driver, the connections reject read-only transactions
+	db := sql.OpenDB(noReadOnlyConnector{dsn: filepath.Join(dir, "test.db"), driver: sqlite.Driver()})
+	defer db.Close()
+
+	handler := NewMCPHandler()
+	handler.SetDatabase(db, DatabaseSettings{})
+
+	tests := []struct {
+		name      string
+		query     string
+		wantError string
+	}{
+		{"query falls back to a plain transaction", "SELECT name FROM users", ""},
+		{"second query", "SELECT id FROM users", ""},
+		{"write statement", "UPDATE users SET name = 'Grace'", "only queries are allowed"},
+		{"select into", "SELECT * INTO users_copy FROM users", "SELECT ... INTO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryPath := filepath.Join(dir, "query.sql")
+			if err := os.WriteFile(queryPath, []byte(tt.query), 0644); err != nil {
+				t.Fatal(err)
+			}
+			resource := &registry.ResourceInfo{Name: "query", FilePath: queryPath, Type: registry.DatabaseResource}
+
+			_, err := handler.AccessResource(resource, []byte(`{}`))
+			if tt.wantError == "" && err != nil {
+				t.Fatalf("AccessResource() error = %v", err)
+			}
+			if tt.wantError != "" && (err == nil || !strings.Contains(err.Error(), tt.wantError)) {
+				t.Fatalf("AccessResource() error = %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+
+	if !handler.database.noReadOnlyTx.Load() {
+		t.Error("Expected the missing read-only support to be remembered")
+	}
+}
+
+// noReadOnlyConnector opens connections that reject read-only transactions
+type noReadOnlyConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c noReadOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return noReadOnlyConn{conn}, nil
+}
+
+func (c noReadOnlyConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// noReadOnlyConn is a connection that rejects read-only transactions
+type noReadOnlyConn struct {
+	driver.Conn
+}
+
+func (c noReadOnlyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		return nil, errors.New("read-only transactions are not supported")
+	}
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func TestBindParameters(t *testing.T) {
+	statement, args, err := bindParameters("SELECT :a::text, ':b', :b /* :c */ FROM t", map[string]interface{}{"a": 1, "b": "x"}, PlaceholderDollar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT $1::text, ':b', $2 /* :c */ FROM t"; statement != want {
+		t.Errorf("statement = %s, want %s", statement, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, "x"}) {
+		t.Errorf("args = %v", args)
+	}
+}
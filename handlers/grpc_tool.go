@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"gin-mcp/registry"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// executeGRPCTool transcodes the tool arguments to protobuf, invokes the gRPC
+// method and returns the response as structured content
+func (h *MCPHandler) executeGRPCTool(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	spec, ok := toolInfo.Handler.(*registry.GRPCToolSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid gRPC tool definition for tool %s", toolInfo.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, spec.TimeoutDuration(executionTimeout(ctx)))
+	defer cancel()
+
+	method, err := spec.MethodDescriptor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve gRPC method for tool %s: %w", toolInfo.Name, err)
+	}
+
+	var data struct {
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	request := dynamicpb.NewMessage(method.Input())
+	if len(data.Arguments) > 0 && string(data.Arguments) != "null" {
+		if err := protojson.Unmarshal(data.Arguments, request); err != nil {
+			return formatErrorResult(fmt.Sprintf("Invalid arguments for %s: %v", method.Input().FullName(), err))
+		}
+	}
+
+	if len(spec.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(spec.Metadata))
+	}
+
+	response := dynamicpb.NewMessage(method.Output())
+	if err := spec.Invoke(ctx, request, response); err != nil {
+		if rpcStatus, ok := status.FromError(err); ok {
+			return formatErrorResult(fmt.Sprintf("gRPC error %s: %s", rpcStatus.Code(), rpcStatus.Message()))
+		}
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	encoded, err := protojson.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode gRPC response: %w", err)
+	}
+
+	log.Printf("✅ gRPC tool %s executed successfully", toolInfo.Name)
+
+	return json.Marshal(map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": string(encoded),
+			},
+		},
+		"structuredContent": json.RawMessage(encoded),
+	})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-mcp/registry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// echoFileDescriptor describes test.Echo/Say, which repeats a text a number of times
+func echoFileDescriptor() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("echo.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("EchoRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("text"), JsonName: proto.String("text"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("times"), JsonName: proto.String("times"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+			{
+				Name: proto.String("EchoResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("texts"), JsonName: proto.String("texts"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Echo"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{Name: proto.String("Say"), InputType: proto.String(".test.EchoRequest"), OutputType: proto.String(".test.EchoResponse")},
+				},
+			},
+		},
+	}
+}
+
+// startEchoServer starts an in-process gRPC server implementing test.Echo with server reflection
+func startEchoServer(t *testing.T, fileProto *descriptorpb.FileDescriptorProto) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveEcho(t, fileProto, listener)
+	return listener.Addr().String()
+}
+
+// serveEcho serves test.Echo with server reflection on a listener
+func serveEcho(t *testing.T, fileProto *descriptorpb.FileDescriptorProto, listener net.Listener) {
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fileProto}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := files.FindDescriptorByName("test.Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := service.(protoreflect.ServiceDescriptor).Methods().ByName("Say")
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Say",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				request := dynamicpb.NewMessage(method.Input())
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+
+				text := request.Get(method.Input().Fields().ByName("text")).String()
+				times := request.Get(method.Input().Fields().ByName("times")).Int()
+				if times < 0 {
+					return nil, status.Error(codes.InvalidArgument, "times must not be negative")
+				}
+
+				response := dynamicpb.NewMessage(method.Output())
+				texts := response.Mutable(method.Output().Fields().ByName("texts")).List()
+				for i := int64(0); i < times; i++ {
+					texts.Append(protoreflect.ValueOfString(text))
+				}
+				return response, nil
+			},
+		}},
+	}, struct{}{})
+
+	reflectionpb.RegisterServerReflectionServer(server, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: files,
+	}))
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+}
+
+func TestMCPHandler_ExecuteGRPCTool(t *testing.T) {
+	fileProto := echoFileDescriptor()
+	target := startEchoServer(t, fileProto)
+
+	dir := t.TempDir()
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fileProto}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "echo.pb"), descriptorSet, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions := map[string]string{
+		"echo_reflection": "target: " + target + "\nmethod: test.Echo/Say\n",
+		"echo_descriptor": "target: " + target + "\nmethod: test.Echo/Say\ndescriptor_set: echo.pb\n",
+	}
+
+	reg := registry.NewRegistry()
+	for name, definition := range definitions {
+		filePath := filepath.Join(dir, name+".grpc.yaml")
+		if err := os.WriteFile(filePath, []byte(definition), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.RegisterTool(name, filePath, name); err != nil {
+			t.Fatalf("RegisterTool(%s) error = %v", name, err)
+		}
+	}
+	defer reg.UnregisterTool("echo_reflection")
+	defer reg.UnregisterTool("echo_descriptor")
+
+	handler := NewMCPHandler()
+
+	for name := range definitions {
+		t.Run(name, func(t *testing.T) {
+			tool, _ := reg.GetTool(name)
+
+			properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+			if _, exists := properties["times"]; !exists {
+				t.Errorf("Expected input schema derived from EchoRequest, got %v", tool.InputSchema)
+			}
+
+			result, err := handler.ExecuteTool(tool, []byte(`{"arguments": {"text": "hi", "times": 2}}`))
+			if err != nil {
+				t.Fatalf("ExecuteTool() error = %v", err)
+			}
+
+			var content struct {
+				StructuredContent struct {
+					Texts []string `json:"texts"`
+				} `json:"structuredContent"`
+			}
+			if err := json.Unmarshal(result, &content); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(content.StructuredContent.Texts, ",") != "hi,hi" {
+				t.Errorf("structuredContent = %s", result)
+			}
+
+			result, err = handler.ExecuteTool(tool, []byte(`{"arguments": {"times": -1}}`))
+			if err != nil {
+				t.Fatalf("ExecuteTool() error = %v", err)
+			}
+			if !strings.Contains(string(result), `"isError":true`) || !strings.Contains(string(result), "InvalidArgument") {
+				t.Errorf("Expected InvalidArgument error result, got %s", result)
+			}
+		})
+	}
+}
+
+func TestMCPHandler_GRPCToolResolvedOnFirstCall(t *testing.T) {
+	// Reserve an address and leave it unserved while the tool is registered
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := listener.Addr().String()
+	listener.Close()
+
+	filePath := filepath.Join(t.TempDir(), "echo.grpc.yaml")
+	if err := os.WriteFile(filePath, []byte("target: "+target+"\nmethod: test.Echo/Say\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := registry.NewRegistry()
+	if err := reg.RegisterTool("echo", filePath, "echo"); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	defer reg.UnregisterTool("echo")
+
+	tool, _ := reg.GetTool("echo")
+	if properties, _ := tool.InputSchema["properties"].(map[string]interface{}); properties["times"] != nil {
+		t.Fatalf("Expected the generic schema before the method is resolved, got %v", tool.InputSchema)
+	}
+
+	if listener, err = net.Listen("tcp", target); err != nil {
+		t.Skipf("Address %s was taken meanwhile: %v", target, err)
+	}
+	serveEcho(t, echoFileDescriptor(), listener)
+
+	// The connection backs off after the failed attempt, so the first calls may still fail
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := NewMCPHandler().ExecuteTool(tool, []byte(`{"arguments": {"text": "hi", "times": 1}}`))
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ExecuteTool() error = %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	tool, _ = reg.GetTool("echo")
+	if properties, _ := tool.InputSchema["properties"].(map[string]interface{}); properties["times"] == nil {
+		t.Errorf("Expected the schema of EchoRequest once resolved, got %v", tool.InputSchema)
+	}
+
+	// Calls through a removed tool fail rather than using a closed connection
+	reg.UnregisterTool("echo")
+	if _, err := NewMCPHandler().ExecuteTool(tool, []byte(`{"arguments": {"text": "hi"}}`)); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("Expected an error for a closed tool, got %v", err)
+	}
+}
@@ -2,47 +2,189 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"os"
 	"os/exec"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"gin-mcp/registry"
 )
 
+// DefaultTimeout bounds an execution whose context has no deadline
+const DefaultTimeout = 30 * time.Second
+
+// DefaultPanicThreshold is the number of consecutive panics after which an
+// in-process tool is quarantined
+const DefaultPanicThreshold = 3
+
+// ErrToolQuarantined is returned when a quarantined tool is executed
+var ErrToolQuarantined = errors.New("tool is quarantined")
+
+// ToolPanicStats contains panic statistics for a single tool
+type ToolPanicStats struct {
+	Panics      int  `json:"panics"`
+	Quarantined bool `json:"quarantined"`
+}
+
+// panicState tracks panics of the current registration of a tool
+type panicState struct {
+	registration uint64
+	total        int
+	consecutive  int
+	quarantined  bool
+}
+
 // MCPHandler handles MCP resource access and tool execution
-type MCPHandler struct{}
+type MCPHandler struct {
+	registry *registry.Registry
+	cache    *resultCache
+
+	subprocessSettings SubprocessSettings
+	secrets            *Secrets
+	database           *database
+
+	apiCache      apiCache
+	notifier      notifier
+	maxInlineSize int64
+
+	interceptors     []Interceptor
+	interceptorMutex sync.RWMutex
+
+	panicThreshold int
+	panics         map[string]*panicState
+	panicMutex     sync.Mutex
+}
 
 // NewMCPHandler creates a new MCP handler
 func NewMCPHandler() *MCPHandler {
-	return &MCPHandler{}
+	return &MCPHandler{
+		cache:          newResultCache(),
+		maxInlineSize:  DefaultMaxInlineSize,
+		panicThreshold: DefaultPanicThreshold,
+		panics:         make(map[string]*panicState),
+	}
+}
+
+// SetRegistry sets the registry used to resolve the tools and resources
+// referenced by pipeline tools
+func (h *MCPHandler) SetRegistry(reg *registry.Registry) {
+	h.registry = reg
+}
+
+// SetPanicThreshold sets the number of consecutive panics after which a tool
+// is quarantined. Values below 1 restore the default threshold.
+func (h *MCPHandler) SetPanicThreshold(threshold int) {
+	if threshold < 1 {
+		threshold = DefaultPanicThreshold
+	}
+
+	h.panicMutex.Lock()
+	defer h.panicMutex.Unlock()
+	h.panicThreshold = threshold
 }
 
 // AccessResource accesses an MCP resource and returns its content
 func (h *MCPHandler) AccessResource(resourceInfo *registry.ResourceInfo, input []byte) ([]byte, error) {
+	return h.AccessResourceContext(context.Background(), resourceInfo, input)
+}
+
+// AccessResourceContext reads an MCP resource through the interceptor chain
+func (h *MCPHandler) AccessResourceContext(ctx context.Context, resourceInfo *registry.ResourceInfo, input []byte) ([]byte, error) {
+	call := &Call{Kind: ResourceRead, Resource: resourceInfo, Input: input}
+	return h.intercept(ctx, call, func(ctx context.Context, call *Call) ([]byte, error) {
+		return h.readResource(ctx, call.Resource, call.Input)
+	})
+}
+
+// readResource reads the content of a resource
+func (h *MCPHandler) readResource(ctx context.Context, resourceInfo *registry.ResourceInfo, input []byte) ([]byte, error) {
 	// Validate input
 	if err := h.ValidateInput(input); err != nil {
 		return nil, fmt.Errorf("invalid input: %w", err)
 	}
 
+	request, err := parseReadRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	query, err := parseQueryRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	if request.partial() && query.active() {
+		return nil, fmt.Errorf("%w: a query cannot be combined with a partial read", ErrInvalidQuery)
+	}
+	format, err := parseFormatRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	if request.partial() && format.Format != "" {
+		return nil, fmt.Errorf("%w: a format conversion cannot be combined with a partial read", ErrUnsupportedFormat)
+	}
+
+	// SQL resources run against the database when one is configured
+	fetched := resourceInfo.Type == registry.APIRResource && resourceInfo.API != nil
+	queried := resourceInfo.Type == registry.DatabaseResource && h.database != nil
+	if request.partial() && (fetched || queried) {
+		return nil, fmt.Errorf("%w: resource %s is not read from a file", ErrInvalidRange, resourceInfo.Name)
+	}
+	if query.active() && queried {
+		return nil, fmt.Errorf("%w: resource %s is an SQL query, pass params instead", ErrInvalidQuery, resourceInfo.Name)
+	}
+
+	if fetched {
+		return h.readAPIResource(ctx, resourceInfo, query, format.Format)
+	}
+	if queried {
+		return h.queryResource(ctx, resourceInfo, input)
+	}
+
+	// Byte ranges, line ranges and tails are streamed from disk
+	if request.partial() {
+		return h.readFileRange(resourceInfo, request)
+	}
+
+	// Queries return the matching subset of JSON, YAML and CSV resources
+	if query.active() {
+		return h.queryFile(resourceInfo, query, format.Format)
+	}
+
+	// Check the size before reading, resources may be large binaries
+	info, err := os.Stat(resourceInfo.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	if info.Size() > h.maxInlineSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, the maximum inline size is %d bytes, read it in parts with a byte range, line range or tail",
+			ErrResourceTooLarge, resourceInfo.Name, info.Size(), h.maxInlineSize)
+	}
+
 	// Read the resource file
-	content, err := ioutil.ReadFile(resourceInfo.FilePath)
+	data, err := os.ReadFile(resourceInfo.FilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read resource file: %w", err)
 	}
 
+	data, mimeType, err := convertContent(resourceInfo.Name, resourceInfo.MimeType, data, format.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := h.resourceContent(resourceInfo.Name, resourceInfo.URI, mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create MCP resource response
 	response := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"uri":       fmt.Sprintf("file://%s", resourceInfo.FilePath),
-				"mime_type": resourceInfo.MimeType,
-				"text":      string(content),
-			},
-		},
+		"contents": []map[string]interface{}{content},
 	}
 
 	return json.Marshal(response)
@@ -50,23 +192,51 @@ func (h *MCPHandler) AccessResource(resourceInfo *registry.ResourceInfo, input [
 
 // ExecuteTool executes an MCP tool with the given input and returns the result
 func (h *MCPHandler) ExecuteTool(toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	return h.ExecuteToolContext(context.Background(), toolInfo, input)
+}
+
+// ExecuteToolContext executes an MCP tool and stops waiting for it when ctx is done
+func (h *MCPHandler) ExecuteToolContext(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	call := &Call{Kind: ToolCall, Tool: toolInfo, Input: input}
+	return h.intercept(ctx, call, h.invokeTool)
+}
+
+// invokeTool validates the input of a tool call and executes it, ending the interceptor chain
+func (h *MCPHandler) invokeTool(ctx context.Context, call *Call) ([]byte, error) {
 	// Validate input
-	if err := h.ValidateInput(input); err != nil {
+	if err := h.ValidateInput(call.Input); err != nil {
 		return nil, fmt.Errorf("invalid input: %w", err)
 	}
 
+	return h.executeCached(call.Tool, call.Input, func() ([]byte, error) {
+		return h.executeTool(ctx, call.Tool, call.Input)
+	})
+}
+
+// executeTool dispatches a tool execution by tool type
+func (h *MCPHandler) executeTool(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
 	switch toolInfo.Type {
 	case registry.GoPluginTool:
-		return h.executeGoPlugin(toolInfo, input)
+		return h.executeGoPlugin(ctx, toolInfo, input)
+	case registry.RouteTool, registry.FuncTool, registry.BuiltinTool:
+		return h.executeToolFunc(ctx, toolInfo, input)
 	case registry.PythonTool:
-		return h.executePythonScript(toolInfo, input)
+		return h.executePythonScript(ctx, toolInfo, input)
+	case registry.HTTPTool:
+		return h.executeHTTPTool(ctx, toolInfo, input)
+	case registry.GRPCTool:
+		return h.executeGRPCTool(ctx, toolInfo, input)
+	case registry.ModelTool:
+		return h.executeModelTool(ctx, toolInfo, input)
+	case registry.PipelineTool:
+		return h.executePipeline(ctx, toolInfo, input)
 	default:
 		return nil, fmt.Errorf("unsupported tool type: %s", toolInfo.Type)
 	}
 }
 
 // executeGoPlugin executes a Go plugin tool
-func (h *MCPHandler) executeGoPlugin(toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+func (h *MCPHandler) executeGoPlugin(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
 	// Get the Execute function from the plugin
 	executeFunc := toolInfo.Handler
 	if executeFunc == nil {
@@ -79,12 +249,61 @@ func (h *MCPHandler) executeGoPlugin(toolInfo *registry.ToolInfo, input []byte)
 		return nil, fmt.Errorf("invalid Execute function signature for tool %s", toolInfo.Name)
 	}
 
-	// Execute the tool with timeout
+	return h.runInProcess(ctx, toolInfo, func(ctx context.Context, input []byte) ([]byte, error) {
+		return execute(input)
+	}, input)
+}
+
+// executeToolFunc executes a tool backed by a registry.ToolFunc
+func (h *MCPHandler) executeToolFunc(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	execute, ok := toolInfo.Handler.(registry.ToolFunc)
+	if !ok {
+		return nil, fmt.Errorf("invalid handler for tool %s", toolInfo.Name)
+	}
+
+	return h.runInProcess(ctx, toolInfo, execute, input)
+}
+
+// runInProcess runs an in-process tool with panic isolation and a timeout
+func (h *MCPHandler) runInProcess(ctx context.Context, toolInfo *registry.ToolInfo, execute registry.ToolFunc, input []byte) ([]byte, error) {
+	if h.isQuarantined(toolInfo) {
+		return nil, fmt.Errorf("%w: %s", ErrToolQuarantined, toolInfo.Name)
+	}
+
+	result, recovered, err := invokeIsolated(ctx, fmt.Sprintf("Tool %s (%s)", toolInfo.Name, toolInfo.Type), execute, input)
+	if recovered != nil {
+		h.recordPanic(toolInfo)
+		return formatErrorResult(fmt.Sprintf("Tool %s panicked: %v", toolInfo.Name, recovered))
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			h.recordSuccess(toolInfo)
+		}
+		return nil, fmt.Errorf("%s execution failed: %w", toolInfo.Type, err)
+	}
+
+	h.recordSuccess(toolInfo)
+	log.Printf("✅ Tool %s (%s) executed successfully", toolInfo.Name, toolInfo.Type)
+	return h.validateAndFormatOutput(result)
+}
+
+// invokeIsolated runs in-process code in its own goroutine with a timeout.
+// Panics are not covered by Gin's recovery middleware, so they are recovered,
+// logged with their stack trace and returned as the recovered value.
+func invokeIsolated(ctx context.Context, label string, execute registry.ToolFunc, input []byte) ([]byte, interface{}, error) {
 	resultChan := make(chan []byte, 1)
 	errChan := make(chan error, 1)
+	panicChan := make(chan interface{}, 1)
 
 	go func() {
-		result, err := execute(input)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Printf("💥 %s panicked: %v\n%s", label, recovered, debug.Stack())
+				panicChan <- recovered
+			}
+		}()
+
+		result, err := execute(ctx, input)
 		if err != nil {
 			errChan <- err
 			return
@@ -95,40 +314,148 @@ func (h *MCPHandler) executeGoPlugin(toolInfo *registry.ToolInfo, input []byte)
 	// Wait for result with timeout
 	select {
 	case result := <-resultChan:
-		log.Printf("✅ Go plugin tool %s executed successfully", toolInfo.Name)
-		return h.validateAndFormatOutput(result)
+		return result, nil, nil
 	case err := <-errChan:
-		return nil, fmt.Errorf("go plugin execution failed: %w", err)
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("go plugin execution timed out after 30 seconds")
+		return nil, nil, err
+	case recovered := <-panicChan:
+		return nil, recovered, nil
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("cancelled: %w", ctx.Err())
+	case <-time.After(executionTimeout(ctx)):
+		return nil, nil, fmt.Errorf("timed out after %s", executionTimeout(ctx))
+	}
+}
+
+// isQuarantined reports whether the registered version of a tool has been quarantined.
+// A tool that was re-registered since its quarantine is released.
+func (h *MCPHandler) isQuarantined(toolInfo *registry.ToolInfo) bool {
+	h.panicMutex.Lock()
+	defer h.panicMutex.Unlock()
+
+	state := h.panicStateFor(toolInfo)
+	return state.quarantined
+}
+
+// recordSuccess resets the consecutive panic count of a tool
+func (h *MCPHandler) recordSuccess(toolInfo *registry.ToolInfo) {
+	h.panicMutex.Lock()
+	defer h.panicMutex.Unlock()
+
+	if state, exists := h.panics[toolInfo.Name]; exists && state.registration == toolInfo.Registration {
+		state.consecutive = 0
 	}
 }
 
+// recordPanic counts a panic and quarantines the tool once the threshold is reached
+func (h *MCPHandler) recordPanic(toolInfo *registry.ToolInfo) {
+	h.panicMutex.Lock()
+	defer h.panicMutex.Unlock()
+
+	state := h.panicStateFor(toolInfo)
+	state.total++
+	state.consecutive++
+
+	if !state.quarantined && state.consecutive >= h.panicThreshold {
+		state.quarantined = true
+		log.Printf("🚫 Tool %s quarantined after %d consecutive panics", toolInfo.Name, state.consecutive)
+	}
+}
+
+// panicStateFor returns the panic state of a tool, resetting it when the tool
+// has been re-registered. The caller must hold panicMutex.
+func (h *MCPHandler) panicStateFor(toolInfo *registry.ToolInfo) *panicState {
+	state, exists := h.panics[toolInfo.Name]
+	if !exists {
+		state = &panicState{registration: toolInfo.Registration}
+		h.panics[toolInfo.Name] = state
+	} else if state.registration != toolInfo.Registration {
+		state.registration = toolInfo.Registration
+		state.consecutive = 0
+		state.quarantined = false
+	}
+	return state
+}
+
+// PanicStats returns panic statistics for every tool that has panicked
+func (h *MCPHandler) PanicStats() map[string]ToolPanicStats {
+	h.panicMutex.Lock()
+	defer h.panicMutex.Unlock()
+
+	stats := make(map[string]ToolPanicStats)
+	for name, state := range h.panics {
+		if state.total == 0 {
+			continue
+		}
+		stats[name] = ToolPanicStats{
+			Panics:      state.total,
+			Quarantined: state.quarantined,
+		}
+	}
+	return stats
+}
+
 // executePythonScript executes a Python script tool
-func (h *MCPHandler) executePythonScript(toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
-	// Create command to execute Python script
-	cmd := exec.Command("python3", toolInfo.FilePath)
+func (h *MCPHandler) executePythonScript(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	if toolInfo.Status == registry.ToolUnavailable {
+		return formatErrorResult(fmt.Sprintf("Tool %s is unavailable: %s", toolInfo.Name, toolInfo.StatusError))
+	}
 
-	// Set up input/output pipes
-	stdin, err := cmd.StdinPipe()
+	interpreter := registry.DefaultPythonInterpreter
+	if toolInfo.Python != nil {
+		interpreter = toolInfo.Python.Interpreter
+	}
+
+	proc, err := h.subprocessFor(toolInfo.Manifest)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+		return formatErrorResult(fmt.Sprintf("Invalid execution settings for tool %s: %v", toolInfo.Name, err))
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	output, err := h.runPython(ctx, interpreter, toolInfo.FilePath, input, proc)
+	var limitErr *SandboxLimitError
+	if errors.As(err, &limitErr) {
+		log.Printf("🚧 Python script tool %s exceeded its %s limit", toolInfo.Name, limitErr.Limit)
+		return formatLimitResult(toolInfo.Name, limitErr)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start Python script: %w", err)
+	log.Printf("✅ Python script tool %s executed successfully", toolInfo.Name)
+
+	return h.validateAndFormatOutput(output)
+}
+
+// runPython runs a Python script with the input on stdin and returns its stdout
+func (h *MCPHandler) runPython(ctx context.Context, interpreter, filePath string, input []byte, proc *subprocess) ([]byte, error) {
+	env, cleanup, err := sandboxTempDir(proc.sandbox, proc.env)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	overflow := make(chan struct{})
+	var overflowOnce sync.Once
+	onExceed := func() { overflowOnce.Do(func() { close(overflow) }) }
+	stdout := &limitedBuffer{limit: proc.sandbox.MaxOutputBytes, onExceed: onExceed}
+	stderr := &limitedBuffer{limit: proc.sandbox.MaxOutputBytes, onExceed: onExceed}
+
+	// The command may be created more than once while the sandbox probes for
+	// network isolation
+	build := func() *exec.Cmd {
+		cmd := exec.Command(interpreter, filePath)
+		cmd.Env = env
+		cmd.Dir = proc.dir
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		return cmd
 	}
 
-	// Send input to the script
-	if _, err := stdin.Write(input); err != nil {
-		return nil, fmt.Errorf("failed to write to stdin: %w", err)
+	// Start the command
+	cmd, err := startSandboxed(build, proc.sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start Python script: %w", err)
 	}
-	stdin.Close()
 
 	// Wait for completion with timeout
 	done := make(chan error, 1)
@@ -138,18 +465,34 @@ func (h *MCPHandler) executePythonScript(toolInfo *registry.ToolInfo, input []by
 
 	select {
 	case err := <-done:
+		if violation := sandboxViolation(proc.sandbox, cmd.ProcessState, stderr.String()); violation != nil {
+			return nil, violation
+		}
 		if err != nil {
-			return nil, fmt.Errorf("Python script execution failed: %w, stderr: %s", err, stderr.String())
+			return nil, fmt.Errorf("Python script execution failed: %w, stderr: %s", err, proc.redact(stderr.String()))
 		}
-	case <-time.After(30 * time.Second):
-		cmd.Process.Kill()
-		return nil, fmt.Errorf("Python script execution timed out after 30 seconds")
+	case <-overflow:
+		killSandboxed(cmd, proc.sandbox)
+		<-done
+		return nil, &SandboxLimitError{Limit: LimitOutput}
+	case <-ctx.Done():
+		killSandboxed(cmd, proc.sandbox)
+		return nil, fmt.Errorf("Python script execution cancelled: %w", ctx.Err())
+	case <-time.After(executionTimeout(ctx)):
+		killSandboxed(cmd, proc.sandbox)
+		return nil, fmt.Errorf("Python script execution timed out after %s", executionTimeout(ctx))
 	}
 
-	output := stdout.Bytes()
-	log.Printf("✅ Python script tool %s executed successfully", toolInfo.Name)
+	return stdout.Bytes(), nil
+}
 
-	return h.validateAndFormatOutput(output)
+// executionTimeout returns how long an execution may run: until the context
+// deadline when one is set, or DefaultTimeout otherwise
+func executionTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return DefaultTimeout
 }
 
 // ValidateInput validates the input JSON
@@ -180,6 +523,21 @@ func (h *MCPHandler) ValidateOutput(output []byte) error {
 	return nil
 }
 
+// formatErrorResult formats a message as an MCP tool result with isError set
+func formatErrorResult(message string) ([]byte, error) {
+	response := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": message,
+			},
+		},
+		"isError": true,
+	}
+
+	return json.Marshal(response)
+}
+
 // validateAndFormatOutput validates and formats the tool output
 func (h *MCPHandler) validateAndFormatOutput(output []byte) ([]byte, error) {
 	// Try to validate as JSON first
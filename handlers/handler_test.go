@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
+
+	"gin-mcp/registry"
 )
 
 func TestMCPHandler_ValidateInput(t *testing.T) {
@@ -135,3 +138,53 @@ func TestMCPHandler_ValidateAndFormatOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPHandler_GoPluginPanicIsolation(t *testing.T) {
+	handler := NewMCPHandler()
+	handler.SetPanicThreshold(2)
+
+	tool := &registry.ToolInfo{
+		Name: "panicky",
+		Type: registry.GoPluginTool,
+		Handler: func(input []byte) ([]byte, error) {
+			panic("boom")
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := handler.ExecuteTool(tool, []byte(`{"arguments": {}}`))
+		if err != nil {
+			t.Fatalf("ExecuteTool() error = %v", err)
+		}
+
+		var content map[string]interface{}
+		if err := json.Unmarshal(result, &content); err != nil {
+			t.Fatalf("Expected JSON result but got error: %v", err)
+		}
+		if content["isError"] != true {
+			t.Errorf("Expected isError result, got %s", result)
+		}
+	}
+
+	if _, err := handler.ExecuteTool(tool, []byte(`{"arguments": {}}`)); !errors.Is(err, ErrToolQuarantined) {
+		t.Errorf("Expected ErrToolQuarantined, got %v", err)
+	}
+
+	stats := handler.PanicStats()["panicky"]
+	if stats.Panics != 2 || !stats.Quarantined {
+		t.Errorf("PanicStats() = %+v, want 2 panics and quarantined", stats)
+	}
+
+	// A copy for a reloaded manifest stays quarantined
+	reloaded := *tool
+	if _, err := handler.ExecuteTool(&reloaded, []byte(`{"arguments": {}}`)); !errors.Is(err, ErrToolQuarantined) {
+		t.Errorf("Expected reloaded tool to stay quarantined, got %v", err)
+	}
+
+	// Re-registering the tool releases the quarantine
+	reregistered := *tool
+	reregistered.Registration++
+	if _, err := handler.ExecuteTool(&reregistered, []byte(`{"arguments": {}}`)); err != nil {
+		t.Errorf("Expected re-registered tool to run, got %v", err)
+	}
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gin-mcp/registry"
+)
+
+// maxHTTPToolResponseSize limits how much of an upstream response is read
+const maxHTTPToolResponseSize = 10 << 20
+
+// executeHTTPTool forwards a tool call to the upstream service described by the tool definition
+func (h *MCPHandler) executeHTTPTool(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	spec, ok := toolInfo.Handler.(*registry.HTTPToolSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid HTTP tool definition for tool %s", toolInfo.Name)
+	}
+
+	arguments, err := parseArguments(input)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := buildHTTPToolRequest(ctx, spec, arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for tool %s: %w", toolInfo.Name, err)
+	}
+
+	client := &http.Client{Timeout: spec.TimeoutDuration(executionTimeout(ctx))}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP tool request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxHTTPToolResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP tool response: %w", err)
+	}
+
+	log.Printf("✅ HTTP tool %s executed with status %d", toolInfo.Name, response.StatusCode)
+	return FormatHTTPResult(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+// parseArguments extracts the "arguments" object from a tool call input
+func parseArguments(input []byte) (map[string]interface{}, error) {
+	var data struct {
+		Arguments map[string]interface{} `json:"arguments"`
+	}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if data.Arguments == nil {
+		data.Arguments = make(map[string]interface{})
+	}
+	return data.Arguments, nil
+}
+
+// buildHTTPToolRequest maps tool arguments onto the upstream URL, query, headers and body
+func buildHTTPToolRequest(ctx context.Context, spec *registry.HTTPToolSpec, arguments map[string]interface{}) (*http.Request, error) {
+	data, err := templateData(spec.InputSchema, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := renderURL(spec.URL, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sendsBody := spec.Method != "GET" && spec.Method != "HEAD" && spec.Method != "DELETE"
+
+	// Without explicit mappings, arguments go to the query for body-less methods
+	// and to the JSON body otherwise. Arguments already used by the URL or header
+	// templates are not repeated in the query.
+	query := target.Query()
+	if spec.Query != nil {
+		for param, argument := range spec.Query {
+			if value, exists := arguments[argument]; exists {
+				query.Set(param, fmt.Sprint(value))
+			}
+		}
+	} else if !sendsBody {
+		templated := templateFields(spec.URL)
+		for _, value := range spec.Headers {
+			for field := range templateFields(value) {
+				templated[field] = true
+			}
+		}
+		for argument, value := range arguments {
+			if !templated[argument] {
+				query.Set(argument, fmt.Sprint(value))
+			}
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	var body io.Reader
+	if sendsBody {
+		payload := arguments
+		if spec.Body != nil {
+			payload = make(map[string]interface{}, len(spec.Body))
+			for field, argument := range spec.Body {
+				if value, exists := arguments[argument]; exists {
+					payload[field] = value
+				}
+			}
+		}
+
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, spec.Method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if sendsBody {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, value := range spec.Headers {
+		rendered, err := renderTemplate(value, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for header %s: %w", name, err)
+		}
+		request.Header.Set(name, rendered)
+	}
+
+	return request, nil
+}
+
+// templateData returns the arguments that templates are evaluated against.
+// Optional arguments declared in the input schema default to "", a missing
+// required argument is an error.
+func templateData(inputSchema map[string]interface{}, arguments map[string]interface{}) (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(arguments))
+	for name, value := range arguments {
+		data[name] = value
+	}
+
+	var required []interface{}
+	switch names := inputSchema["required"].(type) {
+	case []interface{}:
+		required = names
+	case []string:
+		for _, name := range names {
+			required = append(required, name)
+		}
+	}
+	for _, name := range required {
+		if value, exists := arguments[fmt.Sprint(name)]; !exists || value == nil {
+			return nil, fmt.Errorf("missing required argument %v", name)
+		}
+	}
+
+	properties, _ := inputSchema["properties"].(map[string]interface{})
+	for name := range properties {
+		if data[name] == nil {
+			data[name] = ""
+		}
+	}
+	return data, nil
+}
+
+// renderURL evaluates a URL template with the arguments escaped for the part
+// of the URL they appear in, so that they cannot change its structure, and
+// checks that the scheme and host are the ones of the definition
+func renderURL(text string, data map[string]interface{}) (*url.URL, error) {
+	path, query := splitURLTemplate(text)
+
+	renderedPath, err := renderTemplate(path, escapeArguments(data, escapePathSegment).(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url template: %w", err)
+	}
+	renderedQuery, err := renderTemplate(query, escapeArguments(data, url.QueryEscape).(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url template: %w", err)
+	}
+
+	target, err := url.Parse(renderedPath + renderedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	base, err := url.Parse(strings.SplitN(text, "{{", 2)[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if target.Scheme != base.Scheme || target.Host != base.Host || target.User.String() != base.User.String() {
+		return nil, fmt.Errorf("arguments changed the url to %s://%s", target.Scheme, target.Host)
+	}
+	return target, nil
+}
+
+// splitURLTemplate splits a URL template before the "?" starting its query,
+// skipping template actions
+func splitURLTemplate(text string) (string, string) {
+	for i := 0; i < len(text); i++ {
+		if strings.HasPrefix(text[i:], "{{") {
+			end := strings.Index(text[i:], "}}")
+			if end < 0 {
+				break
+			}
+			i += end + 1
+			continue
+		}
+		if text[i] == '?' || text[i] == '#' {
+			return text[:i], text[i:]
+		}
+	}
+	return text, ""
+}
+
+// escapeArguments returns the argument values as strings escaped with escape,
+// keeping the structure of objects and arrays
+func escapeArguments(value interface{}, escape func(string) string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		escaped := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			escaped[key] = escapeArguments(item, escape)
+		}
+		return escaped
+	case []interface{}:
+		escaped := make([]interface{}, len(value))
+		for i, item := range value {
+			escaped[i] = escapeArguments(item, escape)
+		}
+		return escaped
+	case nil:
+		return ""
+	default:
+		return escape(fmt.Sprint(value))
+	}
+}
+
+// escapePathSegment escapes a value for a URL path, including dot segments
+// that would move up the path
+func escapePathSegment(value string) string {
+	if strings.Trim(value, ".") == "" {
+		return strings.ReplaceAll(value, ".", "%2E")
+	}
+	return url.PathEscape(value)
+}
+
+// renderTemplate evaluates a Go template against the tool arguments. Fields
+// missing from the arguments are an error rather than rendering "<no value>".
+func renderTemplate(text string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("value").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// templateFields returns the names of the top-level fields a template refers to
+func templateFields(text string) map[string]bool {
+	fields := make(map[string]bool)
+	if !strings.Contains(text, "{{") {
+		return fields
+	}
+
+	tmpl, err := template.New("value").Parse(text)
+	if err != nil {
+		return fields
+	}
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node != nil {
+				for _, child := range node.Nodes {
+					walk(child)
+				}
+			}
+		case *parse.ActionNode:
+			walk(node.Pipe)
+		case *parse.PipeNode:
+			if node != nil {
+				for _, command := range node.Cmds {
+					walk(command)
+				}
+			}
+		case *parse.CommandNode:
+			for _, argument := range node.Args {
+				walk(argument)
+			}
+		case *parse.FieldNode:
+			fields[node.Ident[0]] = true
+		case *parse.IfNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.RangeNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.WithNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		}
+	}
+	walk(tmpl.Tree.Root)
+	return fields
+}
+
+// FormatHTTPResult maps an HTTP response into MCP tool content. JSON objects are
+// also returned as structured content and error statuses set isError.
+func FormatHTTPResult(statusCode int, contentType string, body []byte) ([]byte, error) {
+	response := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": string(body),
+			},
+		},
+	}
+
+	if strings.Contains(contentType, "json") {
+		var structured map[string]interface{}
+		if err := json.Unmarshal(body, &structured); err == nil {
+			response["structuredContent"] = structured
+		}
+	}
+
+	if statusCode >= 400 {
+		response["isError"] = true
+	}
+
+	return json.Marshal(response)
+}
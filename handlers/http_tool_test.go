@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_ExecuteHTTPTool(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-User") != "alice" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"path": r.URL.Path,
+			"city": r.URL.Query().Get("q"),
+		})
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	definition := "url: " + upstream.URL + "/{{.endpoint}}\n" +
+		"method: get\n" +
+		"headers:\n  X-User: \"{{.user}}\"\n" +
+		"query:\n  q: city\n" +
+		"input_schema:\n  type: object\n  required: [endpoint]\n  properties:\n    endpoint: {type: string}\n    user: {type: string}\n    city: {type: string}\n"
+	filePath := filepath.Join(dir, "weather.http.yaml")
+	if err := os.WriteFile(filePath, []byte(definition), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := registry.NewRegistry()
+	if err := reg.RegisterTool(registry.NameFromPath(filePath), filePath, "weather"); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	tool, exists := reg.GetTool("weather")
+	if !exists || tool.Type != registry.HTTPTool {
+		t.Fatalf("Expected HTTP tool 'weather' to be registered")
+	}
+
+	handler := NewMCPHandler()
+
+	tests := []struct {
+		name      string
+		input     string
+		wantError bool
+		wantErr   bool
+		wantCity  string
+	}{
+		{
+			name:     "mapped query and header",
+			input:    `{"arguments": {"endpoint": "forecast", "user": "alice", "city": "Oslo"}}`,
+			wantCity: "Oslo",
+		},
+		{
+			name:      "upstream error status",
+			input:     `{"arguments": {"endpoint": "missing", "user": "alice"}}`,
+			wantError: true,
+		},
+		{
+			name:    "missing required argument",
+			input:   `{"arguments": {"user": "alice"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.ExecuteTool(tool, []byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExecuteTool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var content map[string]interface{}
+			if err := json.Unmarshal(result, &content); err != nil {
+				t.Fatalf("Expected JSON result but got error: %v", err)
+			}
+
+			if isError, _ := content["isError"].(bool); isError != tt.wantError {
+				t.Errorf("isError = %v, want %v (%s)", isError, tt.wantError, result)
+			}
+
+			if tt.wantCity != "" {
+				structured, _ := content["structuredContent"].(map[string]interface{})
+				if structured["city"] != tt.wantCity {
+					t.Errorf("structuredContent = %v, want city %s", structured, tt.wantCity)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		arguments map[string]interface{}
+		want      string
+		wantError bool
+	}{
+		{"path argument", "http://api.test/v1/{{.id}}/info", map[string]interface{}{"id": "42"}, "http://api.test/v1/42/info", false},
+		{"path traversal", "http://api.test/v1/{{.id}}/info", map[string]interface{}{"id": "../admin?"}, "http://api.test/v1/..%2Fadmin%3F/info", false},
+		{"dot segment", "http://api.test/v1/{{.id}}/info", map[string]interface{}{"id": ".."}, "http://api.test/v1/%2E%2E/info", false},
+		{"query argument", "http://api.test/search?q={{.q}}", map[string]interface{}{"q": "a&admin=1"}, "http://api.test/search?q=a%26admin%3D1", false},
+		{"userinfo", "http://api.test{{.path}}", map[string]interface{}{"path": "@evil.com"}, "", true},
+		{"missing argument", "http://api.test/{{.id}}", map[string]interface{}{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := renderURL(tt.template, tt.arguments)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("renderURL() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err == nil && target.String() != tt.want {
+				t.Errorf("renderURL() = %s, want %s", target, tt.want)
+			}
+		})
+	}
+}
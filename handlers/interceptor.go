@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"gin-mcp/registry"
+)
+
+// ErrAccessDenied may be returned, optionally wrapped, by interceptors that reject a call
+var ErrAccessDenied = errors.New("access denied")
+
+// CallKind distinguishes tool calls from resource reads
+type CallKind string
+
+const (
+	ToolCall     CallKind = "tool"
+	ResourceRead CallKind = "resource"
+)
+
+// Call describes a tool call or resource read passing through the interceptor chain.
+// Interceptors may replace Input before invoking the next handler.
+type Call struct {
+	Kind     CallKind
+	Tool     *registry.ToolInfo     // Set for tool calls
+	Resource *registry.ResourceInfo // Set for resource reads
+	Session  string                 // Client session, if known
+	Input    []byte
+}
+
+// Invoker continues a call with the rest of the chain
+type Invoker func(ctx context.Context, call *Call) ([]byte, error)
+
+// Interceptor runs around tool calls and resource reads. It can inspect or
+// rewrite the call, post-process the output of next, or return its own result
+// or error without calling next.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) ([]byte, error)
+
+// sessionKey is the context key of the client session id
+type sessionKey struct{}
+
+// WithSession returns a context carrying a client session id
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the client session id carried by ctx, if any
+func SessionFromContext(ctx context.Context) string {
+	session, _ := ctx.Value(sessionKey{}).(string)
+	return session
+}
+
+// Use appends interceptors to the chain. Interceptors run in the order they
+// were added, the first one outermost.
+func (h *MCPHandler) Use(interceptors ...Interceptor) {
+	h.interceptorMutex.Lock()
+	defer h.interceptorMutex.Unlock()
+
+	// Copy on write so that calls in flight keep the chain they started with
+	chain := make([]Interceptor, 0, len(h.interceptors)+len(interceptors))
+	chain = append(chain, h.interceptors...)
+	h.interceptors = append(chain, interceptors...)
+}
+
+// intercept runs a call through the interceptor chain, ending with final
+func (h *MCPHandler) intercept(ctx context.Context, call *Call, final Invoker) ([]byte, error) {
+	h.interceptorMutex.RLock()
+	chain := h.interceptors
+	h.interceptorMutex.RUnlock()
+
+	call.Session = SessionFromContext(ctx)
+
+	invoke := final
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, next := chain[i], invoke
+		invoke = func(ctx context.Context, call *Call) ([]byte, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoke(ctx, call)
+}
+
+// PrepareToolCall runs the interceptors of a tool call up to the point where
+// they invoke the tool, so that a call executed later, such as by an
+// asynchronous job, is rejected right away. If the interceptors invoke the
+// tool, run is returned and must be called exactly once: it executes the tool
+// with the cancellation of the context it is given and returns the output of
+// the whole chain. Otherwise the output or error of the interceptors is
+// returned. The interceptors run with ctx, which must not end before run.
+func (h *MCPHandler) PrepareToolCall(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) (run func(ctx context.Context) ([]byte, error), output []byte, err error) {
+	type outcome struct {
+		output []byte
+		err    error
+	}
+	invoked := make(chan struct{})
+	runContexts := make(chan context.Context)
+	done := make(chan outcome, 1)
+
+	go func() {
+		call := &Call{Kind: ToolCall, Tool: toolInfo, Input: input}
+		output, err := h.intercept(ctx, call, func(ctx context.Context, call *Call) ([]byte, error) {
+			close(invoked)
+			runCtx := <-runContexts
+			return h.invokeTool(callContext{Context: runCtx, values: ctx}, call)
+		})
+		done <- outcome{output, err}
+	}()
+
+	select {
+	case result := <-done:
+		return nil, result.output, result.err
+	case <-invoked:
+	}
+
+	return func(ctx context.Context) ([]byte, error) {
+		runContexts <- ctx
+		result := <-done
+		return result.output, result.err
+	}, nil, nil
+}
+
+// callContext is cancelled with the context that runs a prepared tool call,
+// while carrying the values the interceptors passed on
+type callContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value looks up a key in the interceptor context, then in the run context
+func (c callContext) Value(key interface{}) interface{} {
+	if value := c.values.Value(key); value != nil {
+		return value
+	}
+	return c.Context.Value(key)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"gin-mcp/registry"
+)
+
+// Predict runs a model with a JSON input and returns its JSON output
+func (h *MCPHandler) Predict(ctx context.Context, modelInfo *registry.ModelInfo, input []byte) ([]byte, error) {
+	// Validate input
+	if err := h.ValidateInput(input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	var output []byte
+	switch modelInfo.Type {
+	case registry.GoPluginModel:
+		predict, ok := modelInfo.Handler.(func([]byte) ([]byte, error))
+		if !ok {
+			return nil, fmt.Errorf("invalid Predict function signature for model %s", modelInfo.Name)
+		}
+
+		result, recovered, err := invokeIsolated(ctx, fmt.Sprintf("Model %s", modelInfo.Name), func(ctx context.Context, input []byte) ([]byte, error) {
+			return predict(input)
+		}, input)
+		if recovered != nil {
+			return nil, fmt.Errorf("model %s panicked: %v", modelInfo.Name, recovered)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("go plugin prediction failed: %w", err)
+		}
+		output = result
+	case registry.PythonModel:
+		proc, err := h.subprocessFor(nil)
+		if err != nil {
+			return nil, err
+		}
+		result, err := h.runPython(ctx, registry.DefaultPythonInterpreter, modelInfo.FilePath, input, proc)
+		if err != nil {
+			return nil, err
+		}
+		output = result
+	default:
+		return nil, fmt.Errorf("unsupported model type: %s", modelInfo.Type)
+	}
+
+	if err := h.ValidateOutput(output); err != nil {
+		return nil, fmt.Errorf("model %s returned invalid output: %w", modelInfo.Name, err)
+	}
+
+	log.Printf("✅ Model %s (%s) predicted successfully", modelInfo.Name, modelInfo.Type)
+	return output, nil
+}
+
+// executeModelTool runs the model behind a model tool, passing the tool
+// arguments as the model input
+func (h *MCPHandler) executeModelTool(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	modelInfo, ok := toolInfo.Handler.(*registry.ModelInfo)
+	if !ok {
+		return nil, fmt.Errorf("no model found for tool %s", toolInfo.Name)
+	}
+
+	var data struct {
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+	if len(data.Arguments) == 0 {
+		data.Arguments = json.RawMessage("{}")
+	}
+
+	output, err := h.Predict(ctx, modelInfo, data.Arguments)
+	if err != nil {
+		return formatErrorResult(err.Error())
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": string(output),
+			},
+		},
+	}
+
+	// Structured content must be a JSON object
+	var structured map[string]interface{}
+	if err := json.Unmarshal(output, &structured); err == nil {
+		result["structuredContent"] = structured
+	}
+
+	return json.Marshal(result)
+}
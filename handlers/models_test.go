@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_PythonModel(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "double.py")
+	script := "import sys, json\ndata = json.load(sys.stdin)\njson.dump({\"output\": data[\"x\"] * 2}, sys.stdout)\n"
+	if err := os.WriteFile(filePath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := registry.NewRegistry()
+	if err := reg.RegisterModel("double", filePath); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+
+	model, exists := reg.GetModel("double")
+	if !exists {
+		t.Fatal("Expected model 'double' to be registered")
+	}
+
+	handler := NewMCPHandler()
+
+	output, err := handler.Predict(context.Background(), model, []byte(`{"x": 10}`))
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if string(output) != `{"output": 20}` {
+		t.Errorf("Predict() = %s, want {\"output\": 20}", output)
+	}
+
+	tool, exists := reg.GetTool(registry.ModelToolPrefix + "double")
+	if !exists {
+		t.Fatal("Expected model to be exposed as an MCP tool")
+	}
+
+	result, err := handler.ExecuteTool(tool, []byte(`{"arguments": {"x": 4}}`))
+	if err != nil {
+		t.Fatalf("ExecuteTool() error = %v", err)
+	}
+
+	var content struct {
+		StructuredContent map[string]float64 `json:"structuredContent"`
+	}
+	if err := json.Unmarshal(result, &content); err != nil {
+		t.Fatal(err)
+	}
+	if content.StructuredContent["output"] != 8 {
+		t.Errorf("structuredContent = %s", result)
+	}
+
+	reg.UnregisterModel("double")
+	if _, exists := reg.GetTool(registry.ModelToolPrefix + "double"); exists {
+		t.Errorf("Expected model tool to be removed with the model")
+	}
+}
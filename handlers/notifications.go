@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+)
+
+// MethodResourceUpdated is the notification sent when the content of a resource changes
+const MethodResourceUpdated = "notifications/resources/updated"
+
+// notificationBuffer is how many notifications a slow subscriber may fall behind
+const notificationBuffer = 64
+
+// Notification is a server-initiated MCP message
+type Notification struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	Method  string                 `json:"method"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// notifier fans notifications out to subscribers
+type notifier struct {
+	subscribers map[chan Notification]struct{}
+	mutex       sync.Mutex
+}
+
+// Subscribe returns a channel receiving notifications and a function ending
+// the subscription. Notifications are dropped for subscribers that fall behind.
+func (h *MCPHandler) Subscribe() (<-chan Notification, func()) {
+	channel := make(chan Notification, notificationBuffer)
+
+	h.notifier.mutex.Lock()
+	if h.notifier.subscribers == nil {
+		h.notifier.subscribers = make(map[chan Notification]struct{})
+	}
+	h.notifier.subscribers[channel] = struct{}{}
+	h.notifier.mutex.Unlock()
+
+	var once sync.Once
+	return channel, func() {
+		once.Do(func() {
+			h.notifier.mutex.Lock()
+			delete(h.notifier.subscribers, channel)
+			h.notifier.mutex.Unlock()
+			close(channel)
+		})
+	}
+}
+
+// notify sends a notification to all subscribers without blocking
+func (h *MCPHandler) notify(method string, params map[string]interface{}) {
+	notification := Notification{JSONRPC: "2.0", Method: method, Params: params}
+
+	h.notifier.mutex.Lock()
+	defer h.notifier.mutex.Unlock()
+
+	for channel := range h.notifier.subscribers {
+		select {
+		case channel <- notification:
+		default:
+			log.Printf("⚠️  Dropped %s notification for a slow subscriber", method)
+		}
+	}
+}
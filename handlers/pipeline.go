@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"gin-mcp/jsonpath"
+	"gin-mcp/registry"
+)
+
+// pipelineStackKey is the context key holding the names of the pipelines being executed
+type pipelineStackKey struct{}
+
+// executePipeline runs the steps of a pipeline tool in order, mapping earlier
+// outputs into later inputs
+func (h *MCPHandler) executePipeline(ctx context.Context, toolInfo *registry.ToolInfo, input []byte) ([]byte, error) {
+	spec, ok := toolInfo.Handler.(*registry.PipelineSpec)
+	if !ok {
+		return nil, fmt.Errorf("no pipeline definition found for tool %s", toolInfo.Name)
+	}
+	if h.registry == nil {
+		return nil, fmt.Errorf("pipeline tool %s requires a registry", toolInfo.Name)
+	}
+
+	// Guard against pipelines that reference themselves, directly or indirectly
+	stack, _ := ctx.Value(pipelineStackKey{}).([]string)
+	for _, name := range stack {
+		if name == toolInfo.Name {
+			return formatErrorResult(fmt.Sprintf("Pipeline cycle detected: %s -> %s", strings.Join(stack, " -> "), toolInfo.Name))
+		}
+	}
+	ctx = context.WithValue(ctx, pipelineStackKey{}, append(stack[:len(stack):len(stack)], toolInfo.Name))
+
+	arguments, err := parseArguments(input)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make(map[string]interface{})
+	state := map[string]interface{}{
+		"arguments": arguments,
+		"steps":     steps,
+	}
+
+	var last interface{}
+	for _, step := range spec.Steps {
+		if step.When != "" && !evaluateCondition(step.When, state) {
+			log.Printf("⏭️  Skipping pipeline step %s of %s", step.Name, toolInfo.Name)
+			continue
+		}
+
+		output, err := h.runPipelineStep(ctx, step, resolveMapping(step.Arguments, state))
+		if err != nil {
+			return formatErrorResult(fmt.Sprintf("Pipeline step %s failed: %v", step.Name, err))
+		}
+
+		steps[step.Name] = output
+		last = output
+	}
+
+	if spec.Output != nil {
+		return formatPipelineResult(resolveMapping(spec.Output, state))
+	}
+	return formatPipelineResult(last)
+}
+
+// runPipelineStep executes the tool or reads the resource of a step and returns its decoded output
+func (h *MCPHandler) runPipelineStep(ctx context.Context, step registry.PipelineStep, arguments interface{}) (interface{}, error) {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	var output []byte
+	if step.Resource != "" {
+		resource, exists := h.registry.GetResource(step.Resource)
+		if !exists {
+			resource, exists = h.registry.GetResourceByURI(step.Resource)
+		}
+		if !exists {
+			return nil, fmt.Errorf("resource %s not found", step.Resource)
+		}
+
+		body, err := json.Marshal(arguments)
+		if err != nil {
+			return nil, err
+		}
+		if output, err = h.AccessResourceContext(ctx, resource, body); err != nil {
+			return nil, err
+		}
+	} else {
+		tool, exists := h.registry.GetTool(step.Tool)
+		if !exists {
+			return nil, fmt.Errorf("tool %s not found", step.Tool)
+		}
+
+		body, err := json.Marshal(map[string]interface{}{"arguments": arguments})
+		if err != nil {
+			return nil, err
+		}
+		if output, err = h.ExecuteToolContext(ctx, tool, body); err != nil {
+			return nil, err
+		}
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(output, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON output: %w", err)
+	}
+
+	// Tool errors stop the pipeline
+	if result, ok := decoded.(map[string]interface{}); ok && result["isError"] == true {
+		return nil, fmt.Errorf("%s", resultText(result))
+	}
+
+	return decoded, nil
+}
+
+// resolveMapping replaces every JSONPath expression nested in a mapping with its value
+func resolveMapping(value interface{}, state map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if !jsonpath.IsExpression(v) {
+			return v
+		}
+		path, err := jsonpath.Compile(v)
+		if err != nil {
+			return nil
+		}
+		resolved, _ := path.Get(state)
+		return resolved
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[key] = resolveMapping(item, state)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = resolveMapping(item, state)
+		}
+		return resolved
+	default:
+		return v
+	}
+}
+
+// evaluateCondition reports whether the value selected by a condition is truthy.
+// A leading "!" negates the condition.
+func evaluateCondition(condition string, state map[string]interface{}) bool {
+	negate := strings.HasPrefix(condition, "!")
+
+	path, err := jsonpath.Compile(strings.TrimPrefix(condition, "!"))
+	if err != nil {
+		return false
+	}
+	value, found := path.Get(state)
+
+	return (found && truthy(value)) != negate
+}
+
+// truthy reports whether a decoded JSON value is considered true
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+// resultText joins the text content items of an MCP tool result
+func resultText(result map[string]interface{}) string {
+	items, _ := result["content"].([]interface{})
+
+	var texts []string
+	for _, item := range items {
+		if content, ok := item.(map[string]interface{}); ok {
+			if text, ok := content["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// formatPipelineResult returns MCP tool results unchanged and wraps any other value as one
+func formatPipelineResult(value interface{}) ([]byte, error) {
+	if result, ok := value.(map[string]interface{}); ok {
+		if _, hasContent := result["content"]; hasContent {
+			return json.Marshal(result)
+		}
+	}
+
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pipeline output: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": string(text),
+			},
+		},
+	}
+
+	// Structured content must be a JSON object
+	if structured, ok := value.(map[string]interface{}); ok {
+		response["structuredContent"] = structured
+	}
+
+	return json.Marshal(response)
+}
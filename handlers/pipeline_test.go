@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+type countArgs struct {
+	Text string `json:"text"`
+}
+
+type countResult struct {
+	Words int `json:"words"`
+}
+
+type labelArgs struct {
+	Words int    `json:"words"`
+	Label string `json:"label"`
+}
+
+type labelResult struct {
+	Summary string `json:"summary"`
+}
+
+const testPipeline = `
+description: Count the words of the notes resource
+steps:
+  - resource: notes
+  - tool: count_words
+    arguments:
+      text: $.steps.notes.contents[0].text
+  - name: label
+    tool: label_count
+    when: $.arguments.label
+    arguments:
+      words: $.steps.count_words.structuredContent.words
+      label: $.arguments.label
+output:
+  words: $.steps.count_words.structuredContent.words
+  summary: $.steps.label.structuredContent.summary
+`
+
+func TestMCPHandler_ExecutePipeline(t *testing.T) {
+	dir := t.TempDir()
+	reg := registry.NewRegistry()
+
+	notesPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notesPath, []byte("one two three"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterResource("notes", notesPath); err != nil {
+		t.Fatal(err)
+	}
+
+	err := reg.RegisterFunc("count_words", "Count words", func(ctx context.Context, args countArgs) (countResult, error) {
+		return countResult{Words: len(strings.Fields(args.Text))}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = reg.RegisterFunc("label_count", "Label a word count", func(ctx context.Context, args labelArgs) (labelResult, error) {
+		return labelResult{Summary: strings.Repeat(args.Label, args.Words)}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinePath := filepath.Join(dir, "notes_stats.pipeline.yaml")
+	if err := os.WriteFile(pipelinePath, []byte(testPipeline), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterTool("notes_stats", pipelinePath, "notes_stats"); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	handler := NewMCPHandler()
+	handler.SetRegistry(reg)
+	tool, _ := reg.GetTool("notes_stats")
+
+	if tool.Description != "Count the words of the notes resource" {
+		t.Errorf("Description = %q", tool.Description)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		words   int
+		summary interface{}
+	}{
+		{"all steps", `{"arguments": {"label": "x"}}`, 3, "xxx"},
+		{"conditional step skipped", `{"arguments": {}}`, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.ExecuteTool(tool, []byte(tt.input))
+			if err != nil {
+				t.Fatalf("ExecuteTool() error = %v", err)
+			}
+
+			var content struct {
+				IsError           bool `json:"isError"`
+				StructuredContent struct {
+					Words   int         `json:"words"`
+					Summary interface{} `json:"summary"`
+				} `json:"structuredContent"`
+			}
+			if err := json.Unmarshal(result, &content); err != nil {
+				t.Fatal(err)
+			}
+			if content.IsError || content.StructuredContent.Words != tt.words || content.StructuredContent.Summary != tt.summary {
+				t.Errorf("Unexpected result: %s", result)
+			}
+		})
+	}
+
+	t.Run("missing tool", func(t *testing.T) {
+		reg.UnregisterTool("count_words")
+
+		result, err := handler.ExecuteTool(tool, []byte(`{"arguments": {}}`))
+		if err != nil {
+			t.Fatalf("ExecuteTool() error = %v", err)
+		}
+		if !strings.Contains(string(result), `"isError":true`) || !strings.Contains(string(result), "count_words") {
+			t.Errorf("Expected error result naming the missing tool, got %s", result)
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"gin-mcp/jsonpath"
+	"gin-mcp/registry"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxQueryDocumentBytes bounds the size of JSON and YAML documents decoded for a query
+const maxQueryDocumentBytes = 256 << 20
+
+// ErrInvalidQuery is returned, wrapped, for malformed or unsupported resource queries
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryRequest selects a subset of a structured resource in the body of a
+// resource read. JSON and YAML resources take a JSONPath query, CSV resources
+// a column selection and a row filter. Limit applies to both.
+type QueryRequest struct {
+	Query   string   `json:"query,omitempty"`   // JSONPath such as "$.items[?(@.price < 10)].name"
+	Columns []string `json:"columns,omitempty"` // CSV columns to return, in order
+	Filter  string   `json:"filter,omitempty"`  // CSV row condition such as "@.total > 100" or "@['unit price'] < 3"
+	Limit   int      `json:"limit,omitempty"`   // Maximum rows or matches
+}
+
+// active reports whether the request carries a query
+func (q QueryRequest) active() bool {
+	return q.Query != "" || len(q.Columns) > 0 || q.Filter != "" || q.Limit != 0
+}
+
+// parseQueryRequest extracts the query from the body of a resource read
+func parseQueryRequest(input []byte) (QueryRequest, error) {
+	var query QueryRequest
+	if err := json.Unmarshal(input, &query); err != nil {
+		return query, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	if query.Limit < 0 {
+		return query, fmt.Errorf("%w: limit must not be negative", ErrInvalidQuery)
+	}
+	return query, nil
+}
+
+// queryFormat returns the format of a MIME type if it can be queried, or ""
+func queryFormat(mimeType string) string {
+	switch format := contentFormat(mimeType); format {
+	case formatJSON, formatYAML, formatCSV:
+		return format
+	}
+	return ""
+}
+
+// queryFile runs a query against a file resource. CSV files are streamed, JSON
+// and YAML documents are decoded as a whole.
+func (h *MCPHandler) queryFile(resourceInfo *registry.ResourceInfo, query QueryRequest, format string) ([]byte, error) {
+	file, err := os.Open(resourceInfo.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	defer file.Close()
+
+	if queryFormat(resourceInfo.MimeType) != formatCSV {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read resource file: %w", err)
+		}
+		if info.Size() > maxQueryDocumentBytes {
+			return nil, fmt.Errorf("%w: %s is %d bytes, documents up to %d bytes can be queried", ErrResourceTooLarge, resourceInfo.Name, info.Size(), maxQueryDocumentBytes)
+		}
+	}
+
+	return h.queryContent(resourceInfo, resourceInfo.MimeType, file, query, format)
+}
+
+// queryContent runs a query against resource content and builds the response
+// with the matching subset in the format of the resource, or converted to format
+func (h *MCPHandler) queryContent(resourceInfo *registry.ResourceInfo, mimeType string, reader io.Reader, query QueryRequest, format string) ([]byte, error) {
+	var data []byte
+	var info map[string]interface{}
+	var err error
+
+	switch source := queryFormat(mimeType); source {
+	case formatJSON, formatYAML:
+		data, info, err = queryDocument(reader, source, query)
+	case formatCSV:
+		data, info, err = h.queryCSV(reader, query)
+	default:
+		return nil, fmt.Errorf("%w: resource %s is not JSON, YAML or CSV", ErrInvalidQuery, resourceInfo.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, mimeType, err = convertContent(resourceInfo.Name, mimeType, data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := h.resourceContent(resourceInfo.Name, resourceInfo.URI, mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"contents": []map[string]interface{}{content},
+		"query":    info,
+	}
+
+	return json.Marshal(response)
+}
+
+// queryDocument evaluates a JSONPath query against a JSON or YAML document.
+// Indefinite paths return the list of matches, cut to the limit, a limit on a
+// definite path is rejected. JSON numbers are kept as written.
+func queryDocument(reader io.Reader, format string, query QueryRequest) ([]byte, map[string]interface{}, error) {
+	if len(query.Columns) > 0 || query.Filter != "" {
+		return nil, nil, fmt.Errorf("%w: columns and filter apply to CSV resources, use a JSONPath query", ErrInvalidQuery)
+	}
+
+	expression := query.Query
+	if expression == "" {
+		expression = "$"
+	}
+	path, err := jsonpath.Compile(expression)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	if query.Limit > 0 && path.Definite() {
+		return nil, nil, fmt.Errorf("%w: limit applies to queries matching a list of values, use a wildcard, slice, filter or recursive descent", ErrInvalidQuery)
+	}
+
+	source, err := io.ReadAll(io.LimitReader(reader, maxQueryDocumentBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read resource: %w", err)
+	}
+
+	var document interface{}
+	if format == formatJSON {
+		decoder := json.NewDecoder(bytes.NewReader(source))
+		decoder.UseNumber()
+		if err = decoder.Decode(&document); err == nil && decoder.More() {
+			err = errors.New("unexpected data after the document")
+		}
+	} else {
+		err = yaml.Unmarshal(source, &document)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse resource as %s: %w", format, err)
+	}
+
+	result, found := path.Get(document)
+	matches, truncated := 0, false
+	if found {
+		matches = 1
+	}
+	if list, ok := result.([]interface{}); ok && !path.Definite() {
+		matches = len(list)
+		if query.Limit > 0 && len(list) > query.Limit {
+			result, truncated = list[:query.Limit], true
+		}
+	}
+
+	var data []byte
+	if format == formatJSON {
+		data, err = json.Marshal(result)
+	} else {
+		data, err = yaml.Marshal(result)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode query result: %w", err)
+	}
+
+	return data, map[string]interface{}{
+		"query":     expression,
+		"matches":   matches,
+		"truncated": truncated,
+	}, nil
+}
+
+// queryCSV streams the rows of a CSV document, keeping the selected columns of
+// the rows matching the filter until the limit or the maximum inline size is reached
+func (h *MCPHandler) queryCSV(reader io.Reader, query QueryRequest) ([]byte, map[string]interface{}, error) {
+	if query.Query != "" {
+		return nil, nil, fmt.Errorf("%w: JSONPath queries apply to JSON and YAML resources, use columns and filter", ErrInvalidQuery)
+	}
+
+	var filter *jsonpath.Path
+	if query.Filter != "" {
+		var err error
+		if filter, err = jsonpath.Compile("$[?(" + query.Filter + ")]"); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+	}
+
+	records := csv.NewReader(reader)
+	records.FieldsPerRecord = -1
+	records.ReuseRecord = true
+
+	header, err := records.Read()
+	if err == io.EOF {
+		return []byte{}, map[string]interface{}{"rows": 0, "scanned": 0, "truncated": false}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse resource as csv: %w", err)
+	}
+	header = append([]string(nil), header...)
+
+	columns := header
+	indexes := make([]int, len(header))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	if len(query.Columns) > 0 {
+		columns, indexes = query.Columns, make([]int, len(query.Columns))
+		for i, column := range query.Columns {
+			indexes[i] = indexOf(header, column)
+			if indexes[i] < 0 {
+				return nil, nil, fmt.Errorf("%w: unknown column %s", ErrInvalidQuery, column)
+			}
+		}
+	}
+
+	var output bytes.Buffer
+	writer := csv.NewWriter(&output)
+	writer.Write(columns)
+
+	rows, scanned, truncated := 0, 0, false
+	selected := make([]string, len(indexes))
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse resource as csv: %w", err)
+		}
+		scanned++
+
+		if filter != nil && len(filter.Select([]interface{}{typedRow(header, record)})) == 0 {
+			continue
+		}
+		if query.Limit > 0 && rows == query.Limit {
+			truncated = true
+			break
+		}
+
+		for i, index := range indexes {
+			selected[i] = ""
+			if index < len(record) {
+				selected[i] = record[index]
+			}
+		}
+
+		previous := output.Len()
+		writer.Write(selected)
+		writer.Flush()
+		if int64(output.Len()) > h.maxInlineSize {
+			output.Truncate(previous)
+			truncated = true
+			break
+		}
+		rows++
+	}
+	writer.Flush()
+
+	return output.Bytes(), map[string]interface{}{
+		"rows":      rows,
+		"scanned":   scanned,
+		"truncated": truncated,
+	}, nil
+}
+
+// typedRow maps the cells of a CSV record to their columns for filtering,
+// decoding numeric cells so that they compare as numbers
+func typedRow(header, record []string) map[string]interface{} {
+	row := make(map[string]interface{}, len(header))
+	for i, column := range header {
+		if i >= len(record) {
+			break
+		}
+		if _, err := strconv.ParseFloat(record[i], 64); err == nil {
+			row[column] = json.Number(record[i])
+		} else {
+			row[column] = record[i]
+		}
+	}
+	return row
+}
+
+// indexOf returns the position of value in values, or -1
+func indexOf(values []string, value string) int {
+	for i, candidate := range values {
+		if candidate == value {
+			return i
+		}
+	}
+	return -1
+}
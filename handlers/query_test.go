@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_QueryStructuredResource(t *testing.T) {
+	files := map[string]string{
+		"catalog.json": `{"items": [{"name": "pen", "price": 2}, {"name": "lamp", "price": 40}, {"name": "mug", "price": 8}]}`,
+		"config.yaml":  "server:\n  port: 8080\n  hosts: [a, b]\n",
+		"sales.csv":    "region,product,unit price,total\neu,pen,2,120\nus,lamp,40,80\neu,mug,8,300\neu,lamp,40,40\n",
+		"orders.json":  `{"orders": [{"id": 9007199254740993, "total": 1.10}, {"id": 9007199254740992, "total": 2}]}`,
+		"ids.csv":      "id\n9007199254740992\n9007199254740993\n",
+		"notes.md":     "# Notes",
+	}
+
+	dir := t.TempDir()
+	reg := registry.NewRegistry()
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.RegisterResource(registry.NameFromPath(path), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := NewMCPHandler()
+
+	tests := []struct {
+		name     string
+		resource string
+		body     string
+		wantText string
+		wantErr  error
+	}{
+		{"jsonpath filter", "catalog", `{"query": "$.items[?(@.price < 10)].name"}`, `["pen","mug"]`, nil},
+		{"jsonpath limit", "catalog", `{"query": "$.items[*].name", "limit": 1}`, `["pen"]`, nil},
+		{"definite jsonpath", "catalog", `{"query": "$.items[1].price"}`, `40`, nil},
+		{"large integers keep their precision", "orders", `{"query": "$.orders[?(@.id == 9007199254740993)]"}`, `[{"id":9007199254740993,"total":1.10}]`, nil},
+		{"limit on a definite jsonpath", "catalog", `{"query": "$.items[1]", "limit": 1}`, "", ErrInvalidQuery},
+		{"yaml stays yaml", "config", `{"query": "$.server.hosts"}`, "- a\n- b\n", nil},
+		{"csv columns", "sales", `{"columns": ["product", "total"], "limit": 2}`, "product,total\npen,120\nlamp,80\n", nil},
+		{"csv numeric filter", "sales", `{"filter": "@.total >= 120", "columns": ["product"]}`, "product\npen\nmug\n", nil},
+		{"csv quoted column filter", "sales", `{"filter": "@['unit price'] == 40", "columns": ["region"]}`, "region\nus\neu\n", nil},
+		{"csv large integer filter", "ids", `{"filter": "@.id > 9007199254740992"}`, "id\n9007199254740993\n", nil},
+		{"csv string filter", "sales", `{"filter": "@.region == 'us'"}`, "region,product,unit price,total\nus,lamp,40,80\n", nil},
+		{"unknown column", "sales", `{"columns": ["margin"]}`, "", ErrInvalidQuery},
+		{"jsonpath on csv", "sales", `{"query": "$[0]"}`, "", ErrInvalidQuery},
+		{"invalid jsonpath", "catalog", `{"query": "items"}`, "", ErrInvalidQuery},
+		{"unstructured resource", "notes", `{"query": "$"}`, "", ErrInvalidQuery},
+		{"query with a partial read", "sales", `{"filter": "@.total > 1", "tail": {}}`, "", ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, _ := reg.GetResource(tt.resource)
+			result, err := handler.AccessResource(resource, []byte(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AccessResource() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AccessResource() error = %v", err)
+			}
+
+			var response struct {
+				Contents []struct {
+					Text string `json:"text"`
+				} `json:"contents"`
+			}
+			if err := json.Unmarshal(result, &response); err != nil {
+				t.Fatal(err)
+			}
+			if text := response.Contents[0].Text; text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
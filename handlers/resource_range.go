@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"gin-mcp/registry"
+)
+
+// DefaultTailLines is the number of lines of a tail read without a line count
+const DefaultTailLines = 10
+
+// tailChunkSize is how much of a file is read at a time when searching backwards for lines
+const tailChunkSize = 64 << 10
+
+// ErrInvalidRange is returned, wrapped, for malformed partial read requests
+var ErrInvalidRange = errors.New("invalid range")
+
+// ReadRequest selects part of a file resource in the body of a resource read.
+// At most one of Bytes, Lines and Tail may be set; without any the whole file
+// is read.
+type ReadRequest struct {
+	Bytes *ByteRange `json:"bytes,omitempty"`
+	Lines *LineRange `json:"lines,omitempty"`
+	Tail  *TailRange `json:"tail,omitempty"`
+}
+
+// ByteRange selects Length bytes from Offset. A zero Length reads as much as
+// the maximum inline size allows.
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length,omitempty"`
+}
+
+// LineRange selects Count lines from line Start (1-based), counted from the
+// byte Offset, which must be the beginning of a line such as the next offset of
+// a previous read. A zero Count reads as many lines as fit the maximum inline size.
+type LineRange struct {
+	Offset int64 `json:"offset,omitempty"`
+	Start  int64 `json:"start,omitempty"`
+	Count  int64 `json:"count,omitempty"`
+}
+
+// TailRange selects the last Lines lines of a file (default: 10)
+type TailRange struct {
+	Lines int64 `json:"lines,omitempty"`
+}
+
+// partial reports whether the request selects part of the file
+func (r ReadRequest) partial() bool {
+	return r.Bytes != nil || r.Lines != nil || r.Tail != nil
+}
+
+// validate rejects conflicting selectors and negative values
+func (r ReadRequest) validate() error {
+	selectors := 0
+	for _, set := range []bool{r.Bytes != nil, r.Lines != nil, r.Tail != nil} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		return fmt.Errorf("%w: only one of bytes, lines and tail may be set", ErrInvalidRange)
+	}
+
+	switch {
+	case r.Bytes != nil && (r.Bytes.Offset < 0 || r.Bytes.Length < 0):
+		return fmt.Errorf("%w: byte offset and length must not be negative", ErrInvalidRange)
+	case r.Lines != nil && (r.Lines.Offset < 0 || r.Lines.Start < 0 || r.Lines.Count < 0):
+		return fmt.Errorf("%w: line offset, start and count must not be negative", ErrInvalidRange)
+	case r.Tail != nil && r.Tail.Lines < 0:
+		return fmt.Errorf("%w: tail lines must not be negative", ErrInvalidRange)
+	}
+	return nil
+}
+
+// parseReadRequest extracts the partial read selectors from the body of a resource read
+func parseReadRequest(input []byte) (ReadRequest, error) {
+	var request ReadRequest
+	if err := json.Unmarshal(input, &request); err != nil {
+		return request, fmt.Errorf("%w: %v", ErrInvalidRange, err)
+	}
+	return request, request.validate()
+}
+
+// readFileRange reads the part of a file resource selected by the request,
+// streaming from disk so that only the selected part is held in memory. The
+// response carries the position of the part, the total size and the request
+// continuing after it.
+func (h *MCPHandler) readFileRange(resourceInfo *registry.ResourceInfo, request ReadRequest) ([]byte, error) {
+	file, err := os.Open(resourceInfo.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	size := info.Size()
+
+	var data []byte
+	var offset int64
+	var next ReadRequest
+	switch {
+	case request.Bytes != nil:
+		data, offset, err = h.readBytes(file, size, *request.Bytes, resourceInfo.MimeType)
+		next.Bytes = &ByteRange{Offset: offset + int64(len(data)), Length: request.Bytes.Length}
+	case request.Lines != nil:
+		data, offset, err = h.readLines(file, size, *request.Lines)
+		next.Lines = &LineRange{Offset: offset + int64(len(data)), Count: request.Lines.Count}
+	default:
+		data, offset, err = h.readTail(file, size, *request.Tail)
+		// Following a tail continues with whatever is appended to the file
+		next.Bytes = &ByteRange{Offset: offset + int64(len(data))}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := h.resourceContent(resourceInfo.Name, resourceInfo.URI, resourceInfo.MimeType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	end := offset + int64(len(data))
+	rangeInfo := map[string]interface{}{
+		"offset":     offset,
+		"length":     len(data),
+		"total_size": size,
+		"eof":        end >= size,
+	}
+	if request.Bytes == nil {
+		rangeInfo["lines"] = countLines(data)
+	}
+
+	response := map[string]interface{}{
+		"contents": []map[string]interface{}{content},
+		"range":    rangeInfo,
+		"next":     next,
+	}
+
+	return json.Marshal(response)
+}
+
+// readBytes reads a byte range, bounded by the maximum inline size. A text
+// range ending inside a multi-byte character is cut before the character, so
+// that continuing at the next offset keeps characters whole.
+func (h *MCPHandler) readBytes(file *os.File, size int64, byteRange ByteRange, mimeType string) ([]byte, int64, error) {
+	offset := min(byteRange.Offset, size)
+	length := byteRange.Length
+	if length == 0 || length > h.maxInlineSize {
+		length = h.maxInlineSize
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(file, offset, length))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read resource file: %w", err)
+	}
+
+	if offset+int64(len(data)) < size && registry.IsTextMimeType(mimeType) {
+		data = trimPartialRune(data)
+	}
+	return data, offset, nil
+}
+
+// readLines reads a line range, bounded by the maximum inline size
+func (h *MCPHandler) readLines(file *os.File, size int64, lineRange LineRange) ([]byte, int64, error) {
+	offset := min(lineRange.Offset, size)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	reader := bufio.NewReader(file)
+
+	// Skip the lines before the start without holding them
+	for skip := lineRange.Start - 1; skip > 0; skip-- {
+		skipped, err := skipLine(reader)
+		offset += skipped
+		if err == io.EOF {
+			return []byte{}, offset, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read resource file: %w", err)
+		}
+	}
+
+	var data []byte
+	for count := int64(0); lineRange.Count == 0 || count < lineRange.Count; count++ {
+		line, err := reader.Peek(1)
+		if len(line) == 0 && err != nil {
+			break
+		}
+
+		line, err = readLine(reader, h.maxInlineSize-int64(len(data)))
+		if errors.Is(err, ErrResourceTooLarge) {
+			if len(data) > 0 {
+				break
+			}
+			return nil, 0, fmt.Errorf("%w: the line at offset %d exceeds the maximum inline size of %d bytes, read it as a byte range", ErrResourceTooLarge, offset, h.maxInlineSize)
+		}
+		if err != nil && err != io.EOF {
+			return nil, 0, fmt.Errorf("failed to read resource file: %w", err)
+		}
+		data = append(data, line...)
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if data == nil {
+		data = []byte{}
+	}
+	return data, offset, nil
+}
+
+// readTail reads the last lines of a file, or as many of them as fit the maximum inline size
+func (h *MCPHandler) readTail(file *os.File, size int64, tail TailRange) ([]byte, int64, error) {
+	lines := tail.Lines
+	if lines == 0 {
+		lines = DefaultTailLines
+	}
+
+	offset, err := tailOffset(file, size, lines, h.maxInlineSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(file, offset, size-offset))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	return data, offset, nil
+}
+
+// tailOffset searches backwards from the end of a file for the start of its
+// last lines, reading at most maxBytes
+func tailOffset(file io.ReaderAt, size, lines, maxBytes int64) (int64, error) {
+	limit := max(size-maxBytes, 0)
+	buffer := make([]byte, tailChunkSize)
+
+	earliest := int64(-1)
+	found := int64(0)
+	for position := size; position > limit; {
+		n := min(tailChunkSize, position-limit)
+		position -= n
+		if _, err := file.ReadAt(buffer[:n], position); err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read resource file: %w", err)
+		}
+
+		for i := n - 1; i >= 0; i-- {
+			// A trailing newline ends the last line rather than starting another
+			if buffer[i] != '\n' || position+i == size-1 {
+				continue
+			}
+			earliest = position + i
+			if found++; found == lines {
+				return earliest + 1, nil
+			}
+		}
+	}
+
+	switch {
+	case limit == 0:
+		return 0, nil
+	case earliest >= 0:
+		return earliest + 1, nil
+	default:
+		return 0, fmt.Errorf("%w: the last line exceeds the maximum inline size of %d bytes, read it as a byte range", ErrResourceTooLarge, maxBytes)
+	}
+}
+
+// readLine reads a line including its newline, failing with ErrResourceTooLarge
+// once it exceeds limit bytes
+func readLine(reader *bufio.Reader, limit int64) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if int64(len(line)+len(chunk)) > limit {
+			return nil, ErrResourceTooLarge
+		}
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// skipLine discards a line including its newline and returns its length
+func skipLine(reader *bufio.Reader) (int64, error) {
+	var skipped int64
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		skipped += int64(len(chunk))
+		if err != bufio.ErrBufferFull {
+			if err == io.EOF && skipped > 0 {
+				err = nil
+			}
+			return skipped, err
+		}
+	}
+}
+
+// countLines returns the number of lines in data, counting an unterminated last line
+func countLines(data []byte) int {
+	lines := bytes.Count(data, []byte{'\n'})
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// trimPartialRune cuts an incomplete UTF-8 sequence from the end of data
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
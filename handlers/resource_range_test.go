@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_ReadFileRange(t *testing.T) {
+	var log strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&log, "line %03d\n", i) // 9 bytes per line
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte(log.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reg := registry.NewRegistry()
+	if err := reg.RegisterResource("app", path); err != nil {
+		t.Fatal(err)
+	}
+	resource, _ := reg.GetResource("app")
+
+	handler := NewMCPHandler()
+	handler.SetMaxInlineSize(100)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantText   string
+		wantOffset int64
+		wantEOF    bool
+		wantNext   string
+		wantErr    error
+	}{
+		{"byte range", `{"bytes": {"offset": 9, "length": 18}}`, "line 002\nline 003\n", 9, false, `{"bytes":{"offset":27,"length":18}}`, nil},
+		{"byte range bounded by the inline size", `{"bytes": {"offset": 0}}`, log.String()[:100], 0, false, `{"bytes":{"offset":100}}`, nil},
+		{"byte range past the end", `{"bytes": {"offset": 5000}}`, "", 900, true, `{"bytes":{"offset":900}}`, nil},
+		{"line range", `{"lines": {"start": 3, "count": 2}}`, "line 003\nline 004\n", 18, false, `{"lines":{"offset":36,"count":2}}`, nil},
+		{"line range continued", `{"lines": {"offset": 36, "count": 1}}`, "line 005\n", 36, false, `{"lines":{"offset":45,"count":1}}`, nil},
+		{"line range bounded by the inline size", `{"lines": {"start": 90}}`, log.String()[801:], 801, true, `{"lines":{"offset":900}}`, nil},
+		{"tail", `{"tail": {"lines": 2}}`, "line 099\nline 100\n", 882, true, `{"bytes":{"offset":900}}`, nil},
+		{"tail bounded by the inline size", `{"tail": {"lines": 50}}`, log.String()[801:], 801, true, `{"bytes":{"offset":900}}`, nil},
+		{"conflicting selectors", `{"bytes": {}, "tail": {}}`, "", 0, false, "", ErrInvalidRange},
+		{"negative offset", `{"bytes": {"offset": -1}}`, "", 0, false, "", ErrInvalidRange},
+		{"whole file", `{}`, "", 0, false, "", ErrResourceTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.AccessResource(resource, []byte(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AccessResource() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AccessResource() error = %v", err)
+			}
+
+			var response struct {
+				Contents []struct {
+					Text string `json:"text"`
+				} `json:"contents"`
+				Range struct {
+					Offset    int64 `json:"offset"`
+					TotalSize int64 `json:"total_size"`
+					EOF       bool  `json:"eof"`
+				} `json:"range"`
+				Next json.RawMessage `json:"next"`
+			}
+			if err := json.Unmarshal(result, &response); err != nil {
+				t.Fatal(err)
+			}
+
+			if text := response.Contents[0].Text; text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if response.Range.Offset != tt.wantOffset || response.Range.EOF != tt.wantEOF || response.Range.TotalSize != 900 {
+				t.Errorf("range = %+v, want offset %d, eof %v and total size 900", response.Range, tt.wantOffset, tt.wantEOF)
+			}
+			if string(response.Next) != tt.wantNext {
+				t.Errorf("next = %s, want %s", response.Next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestTrimPartialRune(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"abc", "abc"},
+		{"caf\xc3\xa9", "caf\xc3\xa9"},
+		{"caf\xc3", "caf"},
+		{"\xe2\x82", ""},
+		{"x\xf0\x9f\x98", "x"},
+	}
+
+	for _, tt := range tests {
+		if got := string(trimPartialRune([]byte(tt.data))); got != tt.want {
+			t.Errorf("trimPartialRune(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gin-mcp/registry"
+)
+
+// Limits a sandboxed subprocess can exceed
+const (
+	LimitCPUTime   = "cpu_time"
+	LimitMemory    = "memory"
+	LimitOpenFiles = "open_files"
+	LimitProcesses = "processes"
+	LimitOutput    = "output"
+)
+
+// SandboxLimitError reports that a subprocess was stopped for exceeding a sandbox limit
+type SandboxLimitError struct {
+	Limit string
+}
+
+func (e *SandboxLimitError) Error() string {
+	return fmt.Sprintf("sandbox %s limit exceeded", strings.ReplaceAll(e.Limit, "_", " "))
+}
+
+// errOutputLimit stops copying the output of a subprocess beyond its cap
+var errOutputLimit = errors.New("output limit exceeded")
+
+// limitedBuffer collects subprocess output up to a limit. Writes beyond the
+// limit fail and call onExceed, which stops the process.
+type limitedBuffer struct {
+	buffer   bytes.Buffer
+	limit    int64 // 0 is unlimited
+	exceeded bool
+	onExceed func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && int64(b.buffer.Len()+len(p)) > b.limit {
+		b.exceeded = true
+		b.onExceed()
+		n, _ := b.buffer.Write(p[:b.limit-int64(b.buffer.Len())])
+		return n, errOutputLimit
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buffer.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buffer.String()
+}
+
+// sandboxTempDir creates the temporary directory of a sandboxed execution and
+// returns the environment pointing to it along with its cleanup
+func sandboxTempDir(settings registry.SandboxSettings, env []string) ([]string, func(), error) {
+	if !settings.Enabled || settings.TempDir == "" {
+		return env, func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gin-mcp-sandbox-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create sandbox temp directory: %w", err)
+	}
+	cleanup := func() {
+		os.Chmod(dir, 0700)
+		os.RemoveAll(dir)
+	}
+
+	mode := os.FileMode(0700)
+	if settings.TempDir == registry.SandboxTempReadOnly {
+		mode = 0500
+	}
+	if settings.UID != nil || settings.GID != nil {
+		uid, gid := -1, -1
+		if settings.UID != nil {
+			uid = int(*settings.UID)
+		}
+		if settings.GID != nil {
+			gid = int(*settings.GID)
+		}
+		if err := os.Lchown(dir, uid, gid); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to hand over sandbox temp directory: %w", err)
+		}
+	}
+	if err := os.Chmod(dir, mode); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to protect sandbox temp directory: %w", err)
+	}
+
+	sandboxed := make([]string, 0, len(env)+3)
+	for _, variable := range env {
+		name, _, _ := strings.Cut(variable, "=")
+		if name != "TMPDIR" && name != "TMP" && name != "TEMP" {
+			sandboxed = append(sandboxed, variable)
+		}
+	}
+	sandboxed = append(sandboxed, "TEMP="+dir, "TMP="+dir, "TMPDIR="+dir)
+
+	return sandboxed, cleanup, nil
+}
+
+// stderrViolation maps the error output of a failed process to the limit it
+// ran into, for limits that surface as failing system calls
+func stderrViolation(settings registry.SandboxSettings, stderr string) error {
+	switch {
+	case settings.MemoryMB > 0 && (strings.Contains(stderr, "MemoryError") || strings.Contains(stderr, "Cannot allocate memory")):
+		return &SandboxLimitError{Limit: LimitMemory}
+	case settings.OpenFiles > 0 && strings.Contains(stderr, "Too many open files"):
+		return &SandboxLimitError{Limit: LimitOpenFiles}
+	case settings.Processes > 0 && strings.Contains(stderr, "Resource temporarily unavailable"):
+		return &SandboxLimitError{Limit: LimitProcesses}
+	}
+	return nil
+}
+
+// formatLimitResult creates an MCP error result naming the exceeded sandbox limit
+func formatLimitResult(toolName string, limitErr *SandboxLimitError) ([]byte, error) {
+	response := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": fmt.Sprintf("Tool %s stopped: %v", toolName, limitErr),
+			},
+		},
+		"structuredContent": map[string]interface{}{
+			"error": "sandbox_limit_exceeded",
+			"limit": limitErr.Limit,
+		},
+		"isError": true,
+	}
+
+	return json.Marshal(response)
+}
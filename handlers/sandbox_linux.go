@@ -0,0 +1,282 @@
+//go:build linux
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gin-mcp/registry"
+)
+
+// How sandboxed processes are cut off from the network
+const (
+	isolationUnknown int32 = iota
+	isolationNetNS         // A new network namespace, requires CAP_SYS_ADMIN
+	isolationUserNS        // A new network namespace inside an unprivileged user namespace
+	isolationNone          // Network namespaces are unavailable
+)
+
+// networkIsolation caches the first isolation mode that worked
+var networkIsolation atomic.Int32
+
+// Environment of the shim that applies resource limits before executing a
+// sandboxed command. Sandboxed commands start as the server binary, whose
+// init sets the limits and then replaces itself with the command, so the
+// limits are in place before the command runs its first instruction.
+const (
+	shimPathEnv   = "GIN_MCP_SANDBOX_EXEC"      // Command to execute
+	shimLimitsEnv = "GIN_MCP_SANDBOX_RLIMITS"   // resource=soft:hard, comma separated
+	shimStatusEnv = "GIN_MCP_SANDBOX_STATUS_FD" // Descriptor the shim reports failures on
+)
+
+func init() {
+	if path, ok := os.LookupEnv(shimPathEnv); ok {
+		runShim(path)
+	}
+}
+
+// startSandboxed starts the command created by build inside the sandbox
+func startSandboxed(build func() *exec.Cmd, settings registry.SandboxSettings) (*exec.Cmd, error) {
+	if !settings.Enabled {
+		cmd := build()
+		return cmd, cmd.Start()
+	}
+
+	attempts := []int32{isolationNone}
+	if settings.NoNetwork {
+		if mode := networkIsolation.Load(); mode != isolationUnknown {
+			attempts = []int32{mode}
+		} else if settings.UID != nil || settings.GID != nil {
+			// A user namespace only maps the server's own ids
+			attempts = []int32{isolationNetNS, isolationNone}
+		} else {
+			attempts = []int32{isolationNetNS, isolationUserNS, isolationNone}
+		}
+	}
+
+	var err error
+	for _, mode := range attempts {
+		cmd := build()
+		cmd.SysProcAttr = sandboxProcAttr(settings, mode)
+
+		status, shimErr := wrapShim(cmd, settings)
+		if shimErr != nil {
+			return nil, shimErr
+		}
+
+		err = cmd.Start()
+		if status != nil {
+			// Only the child keeps the write end, so reads end once it executed the command
+			cmd.ExtraFiles[len(cmd.ExtraFiles)-1].Close()
+		}
+		if err != nil {
+			if status != nil {
+				status.Close()
+			}
+			if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOSPC) {
+				continue
+			}
+			return nil, err
+		}
+
+		if settings.NoNetwork && networkIsolation.CompareAndSwap(isolationUnknown, mode) && mode == isolationNone {
+			log.Printf("⚠️  Network namespaces are unavailable, sandboxed tools keep network access")
+		}
+
+		if status != nil {
+			failure, _ := io.ReadAll(status)
+			status.Close()
+			if len(failure) > 0 {
+				killSandboxed(cmd, settings)
+				cmd.Wait()
+				return nil, fmt.Errorf("failed to apply sandbox limits: %s", failure)
+			}
+		}
+		return cmd, nil
+	}
+
+	return nil, err
+}
+
+// wrapShim makes cmd start as the shim that applies the resource limits of the
+// sandbox, and returns the read end of the pipe the shim reports failures on.
+// Without limits the command is left as is and no pipe is returned.
+func wrapShim(cmd *exec.Cmd, settings registry.SandboxSettings) (*os.File, error) {
+	limits := sandboxRlimits(settings)
+	if len(limits) == 0 {
+		return nil, nil
+	}
+	if cmd.Err != nil {
+		return nil, cmd.Err
+	}
+
+	encoded := make([]string, len(limits))
+	for i, limit := range limits {
+		encoded[i] = fmt.Sprintf("%d=%d:%d", limit.resource, limit.rlimit.Cur, limit.rlimit.Max)
+	}
+
+	status, statusWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env[:len(env):len(env)],
+		shimPathEnv+"="+cmd.Path,
+		shimLimitsEnv+"="+strings.Join(encoded, ","),
+		shimStatusEnv+"="+strconv.Itoa(3+len(cmd.ExtraFiles)),
+	)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, statusWriter)
+	cmd.Path = "/proc/self/exe"
+
+	return status, nil
+}
+
+// runShim applies the resource limits passed by wrapShim and executes the
+// command in place of the server binary. Failures are written to the status
+// descriptor, which is closed on exec, and end the process.
+func runShim(path string) {
+	fd, err := strconv.Atoi(os.Getenv(shimStatusEnv))
+	if err != nil || fd < 3 {
+		fmt.Fprintf(os.Stderr, "invalid %s\n", shimStatusEnv)
+		os.Exit(126)
+	}
+	status := os.NewFile(uintptr(fd), "sandbox-status")
+	syscall.CloseOnExec(fd)
+	fail := func(format string, args ...interface{}) {
+		fmt.Fprintf(status, format, args...)
+		os.Exit(126)
+	}
+
+	for _, limit := range strings.Split(os.Getenv(shimLimitsEnv), ",") {
+		var resource int
+		var rlimit syscall.Rlimit
+		if _, err := fmt.Sscanf(limit, "%d=%d:%d", &resource, &rlimit.Cur, &rlimit.Max); err != nil {
+			fail("invalid limit %q: %v", limit, err)
+		}
+		// syscall.Setrlimit rather than unix.Setrlimit, so that exec does not
+		// restore the open file limit the Go runtime raised at startup
+		if err := syscall.Setrlimit(resource, &rlimit); err != nil {
+			fail("resource %d: %v", resource, err)
+		}
+	}
+
+	env := make([]string, 0, len(os.Environ()))
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if name != shimPathEnv && name != shimLimitsEnv && name != shimStatusEnv {
+			env = append(env, variable)
+		}
+	}
+
+	err = syscall.Exec(path, os.Args, env)
+	fail("exec %s: %v", path, err)
+}
+
+// sandboxProcAttr puts the process in its own process group and applies the
+// configured credentials and network isolation
+func sandboxProcAttr(settings registry.SandboxSettings, mode int32) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+
+	if settings.UID != nil || settings.GID != nil {
+		credential := &syscall.Credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid())}
+		if settings.UID != nil {
+			credential.Uid = *settings.UID
+		}
+		if settings.GID != nil {
+			credential.Gid = *settings.GID
+		}
+		// Groups stay empty, dropping the supplementary groups of the server
+		attr.Credential = credential
+	}
+
+	switch mode {
+	case isolationNetNS:
+		attr.Cloneflags = syscall.CLONE_NEWNET
+	case isolationUserNS:
+		attr.Cloneflags = syscall.CLONE_NEWNET | syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+
+	return attr
+}
+
+// sandboxRlimit is a resource limit of a sandboxed process
+type sandboxRlimit struct {
+	resource int
+	rlimit   syscall.Rlimit
+}
+
+// sandboxRlimits returns the resource limits the sandbox settings ask for
+func sandboxRlimits(settings registry.SandboxSettings) []sandboxRlimit {
+	limits := []struct {
+		resource int
+		value    uint64
+		slack    uint64
+	}{
+		// The soft CPU limit sends SIGXCPU, the hard one a second later SIGKILL
+		{unix.RLIMIT_CPU, settings.CPUSeconds, 1},
+		{unix.RLIMIT_AS, settings.MemoryMB << 20, 0},
+		{unix.RLIMIT_NOFILE, settings.OpenFiles, 0},
+		{unix.RLIMIT_NPROC, settings.Processes, 0},
+	}
+
+	var rlimits []sandboxRlimit
+	for _, limit := range limits {
+		if limit.value == 0 {
+			continue
+		}
+		rlimits = append(rlimits, sandboxRlimit{
+			resource: limit.resource,
+			rlimit:   syscall.Rlimit{Cur: limit.value, Max: limit.value + limit.slack},
+		})
+	}
+	return rlimits
+}
+
+// killSandboxed kills a process along with everything it started
+func killSandboxed(cmd *exec.Cmd, settings registry.SandboxSettings) {
+	if settings.Enabled {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		return
+	}
+	cmd.Process.Kill()
+}
+
+// sandboxViolation returns the limit a finished process exceeded, if any
+func sandboxViolation(settings registry.SandboxSettings, state *os.ProcessState, stderr string) error {
+	if !settings.Enabled || state == nil || state.Success() {
+		return nil
+	}
+
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		cpuLimit := time.Duration(settings.CPUSeconds) * time.Second
+		switch {
+		case status.Signal() == syscall.SIGXCPU:
+			return &SandboxLimitError{Limit: LimitCPUTime}
+		case status.Signal() == syscall.SIGKILL && cpuLimit > 0 && state.UserTime()+state.SystemTime() >= cpuLimit:
+			return &SandboxLimitError{Limit: LimitCPUTime}
+		}
+	}
+
+	return stderrViolation(settings, stderr)
+}
@@ -0,0 +1,147 @@
+//go:build linux
+
+package handlers
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestMCPHandler_Sandbox(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	tests := []struct {
+		name      string
+		script    string
+		manifest  string
+		wantLimit string
+		wantText  string
+		wantErr   string
+	}{
+		{
+			name:      "cpu time",
+			script:    "while True:\n    pass\n",
+			manifest:  "sandbox:\n  cpu_seconds: 1\n",
+			wantLimit: LimitCPUTime,
+		},
+		{
+			name:      "memory",
+			script:    "data = bytearray(512 * 1024 * 1024)\n",
+			manifest:  "sandbox:\n  memory_mb: 128\n",
+			wantLimit: LimitMemory,
+		},
+		{
+			name:      "open files",
+			script:    "files = [open(__file__) for _ in range(100)]\n",
+			manifest:  "sandbox:\n  open_files: 16\n",
+			wantLimit: LimitOpenFiles,
+		},
+		{
+			name:      "output",
+			script:    "import sys\nwhile True:\n    sys.stdout.write('x' * 1024)\n",
+			manifest:  "sandbox:\n  max_output_bytes: 4096\n",
+			wantLimit: LimitOutput,
+		},
+		{
+			name:     "private temp directory",
+			script:   "import json, os, tempfile\nwith tempfile.NamedTemporaryFile() as f:\n    json.dump({'tmp': os.path.dirname(f.name), 'pgid': os.getpgid(0) == os.getpid()}, __import__('sys').stdout)\n",
+			manifest: "sandbox:\n  temp_dir: private\n",
+			wantText: `"pgid": true`,
+		},
+		{
+			name:     "no network",
+			script:   "import json, socket, sys\ntry:\n    socket.create_connection(('127.0.0.1', " + portOf(listener) + "), timeout=2)\n    json.dump({'network': 'reachable'}, sys.stdout)\nexcept OSError:\n    json.dump({'network': 'blocked'}, sys.stdout)\n",
+			manifest: "sandbox:\n  no_network: true\n",
+			wantText: `"blocked"`,
+		},
+		{
+			name:     "limits set before the script starts",
+			script:   "import json, resource, sys\njson.dump({'nofile': resource.getrlimit(resource.RLIMIT_NOFILE)}, sys.stdout)\n",
+			manifest: "sandbox:\n  open_files: 32\n",
+			wantText: `"nofile": [32, 32]`,
+		},
+		{
+			name:     "limits that cannot be applied fail the launch",
+			script:   "print('{}')\n",
+			manifest: "sandbox:\n  open_files: 1099511627776\n",
+			wantErr:  "failed to apply sandbox limits",
+		},
+		{
+			name:     "uid refused in manifests",
+			script:   "print('{}')\n",
+			manifest: "sandbox:\n  uid: 0\n",
+			wantText: "may only be set by the server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			toolPath := filepath.Join(dir, "sandboxed.py")
+			if err := os.WriteFile(toolPath, []byte(tt.script), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "sandboxed.manifest.yaml"), []byte(tt.manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			reg := registry.NewRegistry()
+			if err := reg.RegisterTool("sandboxed", toolPath, "sandboxed"); err != nil {
+				t.Fatal(err)
+			}
+			tool, _ := reg.GetTool("sandboxed")
+
+			result, err := NewMCPHandler().ExecuteTool(tool, []byte(`{"arguments": {}}`))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteTool() error = %v", err)
+			}
+
+			if tt.wantLimit != "" {
+				var response struct {
+					IsError           bool `json:"isError"`
+					StructuredContent struct {
+						Limit string `json:"limit"`
+					} `json:"structuredContent"`
+				}
+				if err := json.Unmarshal(result, &response); err != nil {
+					t.Fatal(err)
+				}
+				if !response.IsError || response.StructuredContent.Limit != tt.wantLimit {
+					t.Errorf("Expected %s limit error, got %s", tt.wantLimit, result)
+				}
+				return
+			}
+
+			if !strings.Contains(string(result), tt.wantText) {
+				t.Errorf("Expected output containing %s, got %s", tt.wantText, result)
+			}
+		})
+	}
+}
+
+// portOf returns the port of a listener as a string
+func portOf(listener net.Listener) string {
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
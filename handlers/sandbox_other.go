@@ -0,0 +1,33 @@
+//go:build !linux
+
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"gin-mcp/registry"
+)
+
+// startSandboxed starts the command created by build. Sandboxing is only
+// available on Linux, so enabled sandboxes refuse to run rather than run unconfined.
+func startSandboxed(build func() *exec.Cmd, settings registry.SandboxSettings) (*exec.Cmd, error) {
+	if settings.Enabled {
+		return nil, fmt.Errorf("sandboxing is not supported on %s", runtime.GOOS)
+	}
+
+	cmd := build()
+	return cmd, cmd.Start()
+}
+
+// killSandboxed kills a process
+func killSandboxed(cmd *exec.Cmd, settings registry.SandboxSettings) {
+	cmd.Process.Kill()
+}
+
+// sandboxViolation never reports violations since no limits are enforced
+func sandboxViolation(settings registry.SandboxSettings, state *os.ProcessState, stderr string) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secrets resolves secret values by name from a KEY=VALUE secrets file and from
+// server environment variables carrying a prefix. Values are never logged.
+type Secrets struct {
+	file      string
+	envPrefix string
+
+	values  map[string]string
+	modTime time.Time
+	mutex   sync.Mutex
+}
+
+// NewSecrets creates a secret resolver. Either source may be empty. The file is
+// re-read when it changes, so secrets can be rotated without a restart.
+func NewSecrets(file, envPrefix string) (*Secrets, error) {
+	s := &Secrets{file: file, envPrefix: envPrefix}
+	if file != "" {
+		if err := s.reload(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Lookup returns the value of a secret, preferring the secrets file over the environment
+func (s *Secrets) Lookup(name string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	if s.file != "" {
+		s.mutex.Lock()
+		if err := s.reload(); err != nil {
+			// Keep serving the last values that could be read
+			log.Printf("⚠️  Failed to reload secrets file: %v", err)
+		}
+		value, exists := s.values[name]
+		s.mutex.Unlock()
+
+		if exists {
+			return value, true
+		}
+	}
+
+	if s.envPrefix != "" {
+		return os.LookupEnv(s.envPrefix + name)
+	}
+	return "", false
+}
+
+// hides reports whether a server environment variable holds a secret and must
+// not be passed to subprocesses
+func (s *Secrets) hides(name string) bool {
+	return s != nil && s.envPrefix != "" && strings.HasPrefix(name, s.envPrefix)
+}
+
+// reload reads the secrets file if it changed since it was last read
+func (s *Secrets) reload() error {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return fmt.Errorf("failed to read secrets file %s: %w", s.file, err)
+	}
+	if s.values != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	content, err := os.ReadFile(s.file)
+	if err != nil {
+		return fmt.Errorf("failed to read secrets file %s: %w", s.file, err)
+	}
+
+	values, err := parseSecretsFile(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse secrets file %s: %w", s.file, err)
+	}
+
+	s.values = values
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// parseSecretsFile parses KEY=VALUE lines. Blank lines, # comments, an
+// "export " prefix and quotes around values are accepted.
+func parseSecretsFile(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			// Never include the line itself, it may contain a secret
+			return nil, fmt.Errorf("line %d is not a KEY=VALUE pair", lineNumber)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
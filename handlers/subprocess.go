@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gin-mcp/registry"
+)
+
+// SubprocessSettings are the execution defaults of tools that run as
+// subprocesses. Tool manifests override them.
+type SubprocessSettings struct {
+	EnvAllowlist []string                 // Server variables passed through, a trailing * matches a prefix (nil passes all)
+	Env          map[string]string        // Additional variables
+	WorkingDir   string                   // Working directory (empty uses the server's)
+	Sandbox      registry.SandboxSettings // Isolation on Linux
+}
+
+// subprocess is the resolved environment of a single subprocess execution
+type subprocess struct {
+	env     []string
+	dir     string
+	secrets []string // Injected secret values, redacted from error messages
+	sandbox registry.SandboxSettings
+}
+
+// SetSubprocessSettings sets the execution defaults of subprocess tools
+func (h *MCPHandler) SetSubprocessSettings(settings SubprocessSettings) {
+	h.subprocessSettings = settings
+}
+
+// SetSecrets sets the resolver of secrets requested by tool manifests
+func (h *MCPHandler) SetSecrets(secrets *Secrets) {
+	h.secrets = secrets
+}
+
+// subprocessFor resolves the environment and working directory of a subprocess
+// from the handler defaults and an optional tool manifest
+func (h *MCPHandler) subprocessFor(manifest *registry.ToolManifest) (*subprocess, error) {
+	settings := h.subprocessSettings
+	proc := &subprocess{dir: settings.WorkingDir, sandbox: settings.Sandbox}
+
+	allowlist := settings.EnvAllowlist
+	if manifest != nil && manifest.Env != nil && manifest.Env.Allow != nil {
+		allowlist = manifest.Env.Allow
+	}
+
+	env := make(map[string]string)
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if h.secrets.hides(name) {
+			continue
+		}
+		if allowlist == nil || envAllowed(name, allowlist) {
+			env[name] = value
+		}
+	}
+
+	for name, value := range settings.Env {
+		env[name] = value
+	}
+
+	if manifest != nil {
+		if manifest.Env != nil {
+			for name, value := range manifest.Env.Set {
+				env[name] = value
+			}
+		}
+
+		for _, name := range manifest.Secrets {
+			value, exists := h.secrets.Lookup(name)
+			if !exists {
+				return nil, fmt.Errorf("secret %s is not configured", name)
+			}
+			env[name] = value
+			proc.secrets = append(proc.secrets, value)
+		}
+
+		if manifest.WorkingDir != "" {
+			proc.dir = manifest.WorkingDir
+		}
+
+		sandbox, err := settings.Sandbox.Merge(manifest.Sandbox)
+		if err != nil {
+			return nil, err
+		}
+		proc.sandbox = sandbox
+	}
+
+	if proc.dir != "" {
+		if info, err := os.Stat(proc.dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("working directory %s does not exist", proc.dir)
+		}
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		proc.env = append(proc.env, name+"="+env[name])
+	}
+
+	return proc, nil
+}
+
+// redact replaces injected secret values in a message
+func (p *subprocess) redact(message string) string {
+	for _, secret := range p.secrets {
+		if secret != "" {
+			message = strings.ReplaceAll(message, secret, "[REDACTED]")
+		}
+	}
+	return message
+}
+
+// envAllowed reports whether a variable name matches an allowlist
+func envAllowed(name string, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		if prefix, isPrefix := strings.CutSuffix(pattern, "*"); isPrefix {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+const envScript = `import json, os, sys
+args = json.load(sys.stdin)["arguments"]
+if args.get("fail"):
+    sys.stderr.write("token was " + os.environ.get("API_TOKEN", ""))
+    sys.exit(1)
+json.dump({"env": dict(os.environ), "cwd": os.getcwd()}, sys.stdout)
+`
+
+func TestMCPHandler_SubprocessSettings(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "data")
+	if err := os.Mkdir(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	toolPath := filepath.Join(dir, "env.py")
+	if err := os.WriteFile(toolPath, []byte(envScript), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "env:\n  allow: [PATH, TEST_ALLOWED_*]\n  set:\n    GREETING: hello\nsecrets: [API_TOKEN]\nworking_dir: data\n"
+	if err := os.WriteFile(filepath.Join(dir, "env.manifest.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	secretsFile := filepath.Join(dir, "secrets.env")
+	if err := os.WriteFile(secretsFile, []byte("# tool secrets\nexport API_TOKEN=\"s3cr3t-token\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_ALLOWED_VALUE", "visible")
+	t.Setenv("TEST_HIDDEN_VALUE", "hidden")
+	t.Setenv("TEST_SECRET_DB_PASSWORD", "not-for-tools")
+
+	reg := registry.NewRegistry()
+	if err := reg.RegisterTool("env", toolPath, "env"); err != nil {
+		t.Fatal(err)
+	}
+	tool, _ := reg.GetTool("env")
+
+	secrets, err := NewSecrets(secretsFile, "TEST_SECRET_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewMCPHandler()
+	handler.SetSecrets(secrets)
+	handler.SetSubprocessSettings(SubprocessSettings{Env: map[string]string{"GREETING": "overridden", "REGION": "eu"}})
+
+	result, err := handler.ExecuteTool(tool, []byte(`{"arguments": {}}`))
+	if err != nil {
+		t.Fatalf("ExecuteTool() error = %v", err)
+	}
+
+	var output struct {
+		Env map[string]string `json:"env"`
+		Cwd string            `json:"cwd"`
+	}
+	if err := json.Unmarshal(result, &output); err != nil {
+		t.Fatalf("Unexpected output %s: %v", result, err)
+	}
+
+	want := map[string]string{
+		"TEST_ALLOWED_VALUE": "visible",
+		"GREETING":           "hello",
+		"REGION":             "eu",
+		"API_TOKEN":          "s3cr3t-token",
+	}
+	for name, value := range want {
+		if output.Env[name] != value {
+			t.Errorf("env[%s] = %q, want %q", name, output.Env[name], value)
+		}
+	}
+	for _, name := range []string{"TEST_HIDDEN_VALUE", "TEST_SECRET_DB_PASSWORD"} {
+		if _, exists := output.Env[name]; exists {
+			t.Errorf("Expected %s not to be passed to the tool", name)
+		}
+	}
+	if resolved, _ := filepath.EvalSymlinks(dataDir); output.Cwd != resolved && output.Cwd != dataDir {
+		t.Errorf("cwd = %s, want %s", output.Cwd, dataDir)
+	}
+
+	// Secrets are redacted from error messages
+	_, err = handler.ExecuteTool(tool, []byte(`{"arguments": {"fail": true}}`))
+	if err == nil || strings.Contains(err.Error(), "s3cr3t-token") || !strings.Contains(err.Error(), "[REDACTED]") {
+		t.Errorf("Expected redacted error, got %v", err)
+	}
+
+	// Registry exports never contain secret values
+	exported, err := reg.ExportRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(exported), "s3cr3t-token") {
+		t.Error("Registry export contains a secret value")
+	}
+
+	// Tools fail cleanly when a requested secret is missing
+	handler.SetSecrets(nil)
+	result, err = handler.ExecuteTool(tool, []byte(`{"arguments": {}}`))
+	if err != nil || !strings.Contains(string(result), `"isError":true`) || !strings.Contains(string(result), "API_TOKEN") {
+		t.Errorf("Expected missing secret error result, got %s, %v", result, err)
+	}
+}
@@ -0,0 +1,490 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status represents the state of an asynchronous job
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+)
+
+// Done reports whether the status is terminal
+func (s Status) Done() bool {
+	return s == Succeeded || s == Failed || s == Cancelled
+}
+
+// Job is a snapshot of an asynchronous execution
+type Job struct {
+	ID         string          `json:"id"`
+	Target     string          `json:"target"`
+	Session    string          `json:"session,omitempty"` // Client session that submitted the job
+	Status     Status          `json:"status"`
+	Progress   float64         `json:"progress"`
+	Message    string          `json:"message,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"`
+}
+
+// Func is the work performed by a job. It should stop when ctx is done.
+type Func func(ctx context.Context) ([]byte, error)
+
+// Config holds configuration for the job manager
+type Config struct {
+	Timeout   time.Duration // Maximum run time of a job (default: 1h)
+	Retention time.Duration // How long finished jobs are kept (default: 24h)
+	Dir       string        // Directory to persist jobs in (empty keeps jobs in memory only)
+}
+
+// entry is the mutable state of a job held by the manager
+type entry struct {
+	job     Job
+	cancel  context.CancelFunc
+	version int  // Incremented on every change of the job
+	removed bool // Deleted or expired, so it must not be written again
+
+	written int // Version last written to disk, guarded by the persist mutex
+}
+
+// Manager runs jobs in the background and keeps their results for a retention period
+type Manager struct {
+	config   Config
+	jobs     map[string]*entry
+	mutex    sync.RWMutex
+	stopped  bool
+	stopChan chan struct{}
+	stopOnce sync.Once
+
+	persistMutex sync.Mutex // Orders writes and removals of job files, which happen outside mutex
+}
+
+// NewManager creates a job manager, loading persisted jobs when a directory is configured
+func NewManager(config Config) (*Manager, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = time.Hour
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+
+	m := &Manager{
+		config:   config,
+		jobs:     make(map[string]*entry),
+		stopChan: make(chan struct{}),
+	}
+
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create jobs directory %s: %w", config.Dir, err)
+		}
+		if err := m.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	go m.expireLoop()
+	return m, nil
+}
+
+// Submit starts a job for a client session in the background and returns its initial snapshot
+func (m *Manager) Submit(target, session string, fn Func) Job {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.Timeout)
+
+	e := &entry{
+		job: Job{
+			ID:        newID(),
+			Target:    target,
+			Session:   session,
+			Status:    Pending,
+			CreatedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+
+	m.mutex.Lock()
+	m.jobs[e.job.ID] = e
+	e.version++
+	snapshot, version := e.job, e.version
+	m.mutex.Unlock()
+	m.persist(e, snapshot, version)
+
+	go m.run(withReporter(ctx, m, e.job.ID), e, fn)
+
+	log.Printf("📋 Job %s submitted for %s", snapshot.ID, target)
+	return snapshot
+}
+
+// run executes a job and records its outcome
+func (m *Manager) run(ctx context.Context, e *entry, fn Func) {
+	defer e.cancel()
+
+	m.update(e.job.ID, func(job *Job) {
+		now := time.Now()
+		job.Status = Running
+		job.StartedAt = &now
+	})
+
+	result, err := fn(ctx)
+
+	m.update(e.job.ID, func(job *Job) {
+		if job.Status.Done() {
+			return
+		}
+
+		now := time.Now()
+		expires := now.Add(m.config.Retention)
+		job.FinishedAt = &now
+		job.ExpiresAt = &expires
+
+		switch {
+		case ctx.Err() == context.Canceled:
+			job.Status = Cancelled
+			job.Error = "job cancelled"
+		case err != nil:
+			job.Status = Failed
+			job.Error = err.Error()
+		case !json.Valid(result):
+			job.Status = Failed
+			job.Error = "job returned invalid JSON"
+		case isErrorResult(result):
+			// The tool ran but reported an error, its content explains it
+			job.Status = Failed
+			job.Error = errorResultText(result)
+			job.Result = result
+		default:
+			job.Status = Succeeded
+			job.Progress = 1
+			job.Result = result
+		}
+	})
+
+	if job, exists := m.Get(e.job.ID); exists {
+		log.Printf("📋 Job %s finished with status %s", job.ID, job.Status)
+	}
+}
+
+// update applies a change to a job and persists it
+func (m *Manager) update(id string, change func(job *Job)) {
+	m.mutex.Lock()
+	e, exists := m.jobs[id]
+	if !exists {
+		m.mutex.Unlock()
+		return
+	}
+	change(&e.job)
+	e.version++
+	snapshot, version := e.job, e.version
+	m.mutex.Unlock()
+
+	m.persist(e, snapshot, version)
+}
+
+// isErrorResult reports whether a result is an MCP tool result with isError set
+func isErrorResult(result []byte) bool {
+	var toolResult struct {
+		IsError bool `json:"isError"`
+	}
+	return json.Unmarshal(result, &toolResult) == nil && toolResult.IsError
+}
+
+// errorResultText returns the text of an MCP tool error result
+func errorResultText(result []byte) string {
+	var toolResult struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if json.Unmarshal(result, &toolResult) == nil {
+		for _, content := range toolResult.Content {
+			if content.Text != "" {
+				return content.Text
+			}
+		}
+	}
+	return "tool returned an error"
+}
+
+// Get returns a snapshot of a job
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	e, exists := m.jobs[id]
+	if !exists {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// List returns snapshots of all jobs
+func (m *Manager) List() []Job {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		jobs = append(jobs, e.job)
+	}
+	return jobs
+}
+
+// Cancel stops a pending or running job
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mutex.Lock()
+
+	e, exists := m.jobs[id]
+	if !exists {
+		m.mutex.Unlock()
+		return Job{}, fmt.Errorf("job %s not found", id)
+	}
+	if e.job.Status.Done() {
+		m.mutex.Unlock()
+		return e.job, fmt.Errorf("job %s already finished with status %s", id, e.job.Status)
+	}
+
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	now := time.Now()
+	expires := now.Add(m.config.Retention)
+	e.job.Status = Cancelled
+	e.job.Error = "job cancelled"
+	e.job.FinishedAt = &now
+	e.job.ExpiresAt = &expires
+	e.version++
+	snapshot, version := e.job, e.version
+	m.mutex.Unlock()
+
+	m.persist(e, snapshot, version)
+
+	log.Printf("🛑 Job %s cancelled", id)
+	return snapshot, nil
+}
+
+// Delete cancels a job if it is still running and removes it with its result
+func (m *Manager) Delete(id string) bool {
+	m.mutex.Lock()
+	e, exists := m.jobs[id]
+	if !exists {
+		m.mutex.Unlock()
+		return false
+	}
+
+	if e.cancel != nil {
+		e.cancel()
+	}
+	m.remove(e)
+	m.mutex.Unlock()
+
+	m.removeFile(id)
+	return true
+}
+
+// Stop cancels all running jobs and stops the expiry loop. Jobs are no longer
+// persisted afterwards, so unfinished jobs are reported as interrupted on restart.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopChan)
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		m.stopped = true
+		for _, e := range m.jobs {
+			if e.cancel != nil && !e.job.Status.Done() {
+				e.cancel()
+			}
+		}
+	})
+}
+
+// Count returns the number of jobs per status
+func (m *Manager) Count() map[Status]int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	counts := make(map[Status]int)
+	for _, e := range m.jobs {
+		counts[e.job.Status]++
+	}
+	return counts
+}
+
+// expireLoop periodically removes finished jobs past their retention period
+func (m *Manager) expireLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.expire(time.Now())
+		case <-m.stopChan:
+			return
+		}
+	}
+}
+
+// expire removes finished jobs that expired before now
+func (m *Manager) expire(now time.Time) {
+	m.mutex.Lock()
+	var expired []string
+	for id, e := range m.jobs {
+		if e.job.ExpiresAt != nil && e.job.ExpiresAt.Before(now) {
+			m.remove(e)
+			expired = append(expired, id)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, id := range expired {
+		m.removeFile(id)
+		log.Printf("🗑️  Job %s expired", id)
+	}
+}
+
+// remove deletes a job from memory, its file is removed with removeFile once
+// the mutex is released. The caller must hold the mutex.
+func (m *Manager) remove(e *entry) {
+	e.removed = true
+	delete(m.jobs, e.job.ID)
+}
+
+// removeFile deletes the file of a removed job when persistence is enabled
+func (m *Manager) removeFile(id string) {
+	if m.config.Dir == "" {
+		return
+	}
+
+	m.persistMutex.Lock()
+	defer m.persistMutex.Unlock()
+
+	m.mutex.RLock()
+	stopped := m.stopped
+	m.mutex.RUnlock()
+	if stopped {
+		return
+	}
+
+	if err := os.Remove(m.jobPath(id)); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️  Failed to remove job file for %s: %v", id, err)
+	}
+}
+
+// persist writes a snapshot of a job to disk when persistence is enabled. It
+// is called without holding the mutex; snapshots older than the one already
+// written and jobs removed meanwhile are skipped.
+func (m *Manager) persist(e *entry, job Job, version int) {
+	if m.config.Dir == "" {
+		return
+	}
+
+	m.persistMutex.Lock()
+	defer m.persistMutex.Unlock()
+
+	m.mutex.RLock()
+	skip := m.stopped || e.removed
+	m.mutex.RUnlock()
+	if skip || version <= e.written {
+		return
+	}
+
+	if err := m.writeJob(job); err != nil {
+		log.Printf("⚠️  Failed to persist job %s: %v", job.ID, err)
+		return
+	}
+	e.written = version
+}
+
+// writeJob writes a job to its file
+func (m *Manager) writeJob(job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial job
+	path := m.jobPath(job.ID)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// load restores persisted jobs. Jobs that did not finish before the server
+// stopped are marked as failed.
+func (m *Manager) load() error {
+	entries, err := os.ReadDir(m.config.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read jobs directory %s: %w", m.config.Dir, err)
+	}
+
+	now := time.Now()
+	for _, dirEntry := range entries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(m.config.Dir, dirEntry.Name()))
+		if err != nil {
+			log.Printf("⚠️  Failed to read job file %s: %v", dirEntry.Name(), err)
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			log.Printf("⚠️  Ignoring invalid job file %s", dirEntry.Name())
+			continue
+		}
+
+		e := &entry{job: job}
+		if !job.Status.Done() {
+			expires := now.Add(m.config.Retention)
+			e.job.Status = Failed
+			e.job.Error = "job interrupted by server restart"
+			e.job.FinishedAt = &now
+			e.job.ExpiresAt = &expires
+			if err := m.writeJob(e.job); err != nil {
+				log.Printf("⚠️  Failed to persist job %s: %v", job.ID, err)
+			}
+		}
+		m.jobs[job.ID] = e
+	}
+
+	log.Printf("📋 Loaded %d persisted jobs from %s", len(m.jobs), m.config.Dir)
+	return nil
+}
+
+// jobPath returns the file a job is persisted to
+func (m *Manager) jobPath(id string) string {
+	return filepath.Join(m.config.Dir, id+".json")
+}
+
+// newID generates a random job identifier
+func newID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
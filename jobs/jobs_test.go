@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitFor polls a job until it reaches a terminal status
+func waitFor(t *testing.T, m *Manager, id string) Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, exists := m.Get(id); exists && job.Status.Done() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return Job{}
+}
+
+func TestManager_Lifecycle(t *testing.T) {
+	m, err := NewManager(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	tests := []struct {
+		name     string
+		fn       Func
+		status   Status
+		result   string
+		errorMsg string
+	}{
+		{
+			name: "succeeded",
+			fn: func(ctx context.Context) ([]byte, error) {
+				ReportProgress(ctx, 0.5, "halfway")
+				return []byte(`{"ok":true}`), nil
+			},
+			status: Succeeded,
+			result: `{"ok":true}`,
+		},
+		{
+			name: "failed",
+			fn: func(ctx context.Context) ([]byte, error) {
+				return nil, errors.New("boom")
+			},
+			status:   Failed,
+			errorMsg: "boom",
+		},
+		{
+			name: "invalid output",
+			fn: func(ctx context.Context) ([]byte, error) {
+				return []byte("not json"), nil
+			},
+			status:   Failed,
+			errorMsg: "job returned invalid JSON",
+		},
+		{
+			name: "tool error result",
+			fn: func(ctx context.Context) ([]byte, error) {
+				return []byte(`{"content":[{"type":"text","text":"city not found"}],"isError":true}`), nil
+			},
+			status:   Failed,
+			result:   `{"content":[{"type":"text","text":"city not found"}],"isError":true}`,
+			errorMsg: "city not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := waitFor(t, m, m.Submit("tool", "", tt.fn).ID)
+
+			if job.Status != tt.status {
+				t.Errorf("Status = %s, want %s", job.Status, tt.status)
+			}
+			if string(job.Result) != tt.result {
+				t.Errorf("Result = %s, want %s", job.Result, tt.result)
+			}
+			if job.Error != tt.errorMsg {
+				t.Errorf("Error = %q, want %q", job.Error, tt.errorMsg)
+			}
+			if job.ExpiresAt == nil {
+				t.Error("Expected finished job to have an expiry")
+			}
+		})
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	m, err := NewManager(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	job := m.Submit("slow", "", func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if _, err := m.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if job = waitFor(t, m, job.ID); job.Status != Cancelled {
+		t.Errorf("Status = %s, want %s", job.Status, Cancelled)
+	}
+	if _, err := m.Cancel(job.ID); err == nil {
+		t.Error("Expected error cancelling a finished job")
+	}
+
+	m.expire(time.Now().Add(48 * time.Hour))
+	if _, exists := m.Get(job.ID); exists {
+		t.Error("Expected expired job to be removed")
+	}
+}
+
+func TestManager_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := NewManager(Config{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := m.Submit("fast", "session-1", func(ctx context.Context) ([]byte, error) {
+		return []byte(`{"value":1}`), nil
+	})
+	waitFor(t, m, done.ID)
+
+	release := make(chan struct{})
+	running := m.Submit("slow", "", func(ctx context.Context) ([]byte, error) {
+		<-release
+		return []byte(`{}`), nil
+	})
+	m.Stop()
+
+	// A new manager simulates a server restart while the slow job was running
+	restarted, err := NewManager(Config{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Stop()
+	close(release)
+
+	job, exists := restarted.Get(done.ID)
+	if !exists || job.Status != Succeeded || string(job.Result) != `{"value":1}` || job.Session != "session-1" {
+		t.Errorf("Expected persisted result, got %+v", job)
+	}
+
+	job, exists = restarted.Get(running.ID)
+	if !exists || job.Status != Failed {
+		t.Errorf("Expected interrupted job to be failed, got %+v", job)
+	}
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import "context"
+
+// reporterKey is the context key under which a job's progress reporter is stored
+type reporterKey struct{}
+
+// reporter updates the progress of a single job
+type reporter struct {
+	manager *Manager
+	id      string
+}
+
+// withReporter attaches a progress reporter for a job to ctx
+func withReporter(ctx context.Context, manager *Manager, id string) context.Context {
+	return context.WithValue(ctx, reporterKey{}, &reporter{manager: manager, id: id})
+}
+
+// ReportProgress records the progress (0 to 1) and an optional message for the
+// job running under ctx. It does nothing when ctx does not belong to a job.
+func ReportProgress(ctx context.Context, progress float64, message string) {
+	r, ok := ctx.Value(reporterKey{}).(*reporter)
+	if !ok {
+		return
+	}
+
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+
+	r.manager.update(r.id, func(job *Job) {
+		if job.Status.Done() {
+			return
+		}
+		job.Progress = progress
+		if message != "" {
+			job.Message = message
+		}
+	})
+}
@@ -0,0 +1,506 @@
+// Package jsonpath evaluates a subset of JSONPath against decoded JSON or YAML
+// documents (maps, slices and scalars). Numbers may be decoded as float64,
+// integers or json.Number, they are compared exactly.
+//
+// Supported syntax: the root $, child access .name and ['name'], wildcards
+// .* and [*], array indexes [n] (negative from the end), slices [start:end],
+// recursive descent ..name and filters [?(@.field op literal)] with the
+// operators ==, !=, <, <=, > and >=, or [?(@.field)] to test for existence.
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	childSegment segmentKind = iota
+	wildcardSegment
+	indexSegment
+	sliceSegment
+	filterSegment
+)
+
+// segment is a single selector of a path
+type segment struct {
+	kind      segmentKind
+	recursive bool
+	name      string
+	index     int
+	start     *int
+	end       *int
+	filter    *filter
+}
+
+// filter is a [?(...)] condition evaluated against each candidate element
+type filter struct {
+	path     *Path
+	operator string
+	value    interface{}
+}
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// IsExpression reports whether s looks like a JSONPath expression rather than a literal string
+func IsExpression(s string) bool {
+	return s == "$" || strings.HasPrefix(s, "$.") || strings.HasPrefix(s, "$[")
+}
+
+// Compile parses a JSONPath expression
+func Compile(expr string) (*Path, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath %q must start with $", expr)
+	}
+
+	p := &Path{expr: expr}
+	for i := 1; i < len(expr); {
+		var seg segment
+		var err error
+
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			i += 2
+			if i < len(expr) && expr[i] == '[' {
+				seg, i, err = parseBracket(expr, i)
+			} else {
+				seg, i, err = parseDotted(expr, i)
+			}
+			seg.recursive = true
+		case expr[i] == '.':
+			seg, i, err = parseDotted(expr, i+1)
+		case expr[i] == '[':
+			seg, i, err = parseBracket(expr, i)
+		default:
+			err = fmt.Errorf("unexpected character %q at offset %d", expr[i], i)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %q: %w", expr, err)
+		}
+		p.segments = append(p.segments, seg)
+	}
+
+	return p, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid
+func MustCompile(expr string) *Path {
+	p, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Definite reports whether the path selects at most one value
+func (p *Path) Definite() bool {
+	for _, seg := range p.segments {
+		if seg.recursive || (seg.kind != childSegment && seg.kind != indexSegment) {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns every value matched by the path
+func (p *Path) Select(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, seg := range p.segments {
+		var next []interface{}
+		for _, node := range nodes {
+			if seg.recursive {
+				for _, descendant := range descendants(node) {
+					next = append(next, seg.apply(descendant)...)
+				}
+			} else {
+				next = append(next, seg.apply(node)...)
+			}
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// Get returns the value of a definite path, or the list of matches of an
+// indefinite one. The boolean is false when a definite path matched nothing.
+func (p *Path) Get(doc interface{}) (interface{}, bool) {
+	matches := p.Select(doc)
+	if !p.Definite() {
+		if matches == nil {
+			matches = []interface{}{}
+		}
+		return matches, true
+	}
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0], true
+}
+
+// apply evaluates a segment against a single node
+func (seg segment) apply(node interface{}) []interface{} {
+	switch seg.kind {
+	case childSegment:
+		if object, ok := node.(map[string]interface{}); ok {
+			if value, exists := object[seg.name]; exists {
+				return []interface{}{value}
+			}
+		}
+	case wildcardSegment:
+		return children(node)
+	case indexSegment:
+		if list, ok := node.([]interface{}); ok {
+			index := seg.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index >= 0 && index < len(list) {
+				return []interface{}{list[index]}
+			}
+		}
+	case sliceSegment:
+		if list, ok := node.([]interface{}); ok {
+			start, end := sliceBounds(seg.start, seg.end, len(list))
+			if start < end {
+				return append([]interface{}(nil), list[start:end]...)
+			}
+		}
+	case filterSegment:
+		var matches []interface{}
+		for _, child := range children(node) {
+			if seg.filter.matches(child) {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+// matches reports whether a candidate element satisfies the filter
+func (f *filter) matches(node interface{}) bool {
+	value, found := f.path.Get(node)
+	if f.operator == "" {
+		return found
+	}
+	if !found {
+		return f.operator == "!="
+	}
+
+	switch f.operator {
+	case "==":
+		return equal(value, f.value)
+	case "!=":
+		return !equal(value, f.value)
+	}
+
+	if left, ok := toRat(value); ok {
+		if right, ok := toRat(f.value); ok {
+			order := left.Cmp(right)
+			return compare(f.operator, order < 0, order == 0)
+		}
+	}
+	if left, ok := value.(string); ok {
+		if right, ok := f.value.(string); ok {
+			return compare(f.operator, left < right, left == right)
+		}
+	}
+	return false
+}
+
+// compare evaluates an ordering operator from the less and equal results
+func compare(operator string, less, equal bool) bool {
+	switch operator {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+// equal compares two scalar values, treating all numeric types alike
+func equal(a, b interface{}) bool {
+	if left, ok := toRat(a); ok {
+		right, ok := toRat(b)
+		return ok && left.Cmp(right) == 0
+	}
+	return a == b
+}
+
+// toRat converts the numeric types produced by JSON and YAML decoders to an
+// exact rational, so that large integers are not rounded
+func toRat(value interface{}) (*big.Rat, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(v))
+	case float64:
+		r := new(big.Rat).SetFloat64(v)
+		return r, r != nil
+	case float32:
+		r := new(big.Rat).SetFloat64(float64(v))
+		return r, r != nil
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	}
+	return nil, false
+}
+
+// children returns the elements of a list or the values of an object in key order
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns a node followed by all nodes nested inside it
+func descendants(node interface{}) []interface{} {
+	nodes := []interface{}{node}
+	for _, child := range children(node) {
+		nodes = append(nodes, descendants(child)...)
+	}
+	return nodes
+}
+
+// sliceBounds resolves optional, possibly negative slice bounds against a length
+func sliceBounds(start, end *int, length int) (int, int) {
+	resolve := func(bound *int, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+		value := *bound
+		if value < 0 {
+			value += length
+		}
+		if value < 0 {
+			return 0
+		}
+		if value > length {
+			return length
+		}
+		return value
+	}
+	return resolve(start, 0), resolve(end, length)
+}
+
+// parseDotted parses the selector following a dot: a name or *
+func parseDotted(expr string, i int) (segment, int, error) {
+	if i < len(expr) && expr[i] == '*' {
+		return segment{kind: wildcardSegment}, i + 1, nil
+	}
+
+	end := i
+	for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+		end++
+	}
+	if end == i {
+		return segment{}, i, fmt.Errorf("missing name at offset %d", i)
+	}
+	return segment{kind: childSegment, name: expr[i:end]}, end, nil
+}
+
+// parseBracket parses a [...] selector starting at the opening bracket
+func parseBracket(expr string, i int) (segment, int, error) {
+	end, err := closingBracket(expr, i)
+	if err != nil {
+		return segment{}, i, err
+	}
+	content := strings.TrimSpace(expr[i+1 : end])
+	next := end + 1
+
+	switch {
+	case content == "*":
+		return segment{kind: wildcardSegment}, next, nil
+	case strings.HasPrefix(content, "?"):
+		f, err := parseFilter(content[1:])
+		if err != nil {
+			return segment{}, i, err
+		}
+		return segment{kind: filterSegment, filter: f}, next, nil
+	case isQuoted(content):
+		return segment{kind: childSegment, name: content[1 : len(content)-1]}, next, nil
+	case strings.Contains(content, ":"):
+		parts := strings.SplitN(content, ":", 2)
+		start, err := optionalInt(parts[0])
+		if err != nil {
+			return segment{}, i, err
+		}
+		stop, err := optionalInt(parts[1])
+		if err != nil {
+			return segment{}, i, err
+		}
+		return segment{kind: sliceSegment, start: start, end: stop}, next, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return segment{}, i, fmt.Errorf("invalid selector [%s]", content)
+	}
+	return segment{kind: indexSegment, index: index}, next, nil
+}
+
+// closingBracket finds the bracket closing the one at i, skipping quoted strings and parentheses
+func closingBracket(expr string, i int) (int, error) {
+	depth := 0
+	var quote byte
+	for j := i + 1; j < len(expr); j++ {
+		c := expr[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ']' && depth == 0:
+			return j, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated [ at offset %d", i)
+}
+
+// filterOperators are checked in order so that two-character operators win
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses the (@.path op literal) part of a filter selector
+func parseFilter(content string) (*filter, error) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "(") || !strings.HasSuffix(content, ")") {
+		return nil, fmt.Errorf("filter must be enclosed in parentheses: %s", content)
+	}
+	content = strings.TrimSpace(content[1 : len(content)-1])
+
+	left, operator, right := content, "", ""
+	for _, op := range filterOperators {
+		if index := indexOutsideQuotes(content, op); index >= 0 {
+			left = strings.TrimSpace(content[:index])
+			operator = op
+			right = strings.TrimSpace(content[index+len(op):])
+			break
+		}
+	}
+
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter must start with @: %s", content)
+	}
+	path, err := Compile("$" + left[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	f := &filter{path: path, operator: operator}
+	if operator != "" {
+		value, err := parseLiteral(right)
+		if err != nil {
+			return nil, err
+		}
+		f.value = value
+	}
+	return f, nil
+}
+
+// parseLiteral parses a quoted string, number, boolean or null
+func parseLiteral(s string) (interface{}, error) {
+	switch {
+	case isQuoted(s):
+		return s[1 : len(s)-1], nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s == "null":
+		return nil, nil
+	}
+
+	// Numbers are kept as written and compared exactly
+	if _, err := strconv.ParseFloat(s, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("invalid literal %q", s)
+	}
+	if _, ok := new(big.Rat).SetString(s); !ok {
+		return nil, fmt.Errorf("invalid literal %q", s)
+	}
+	return json.Number(s), nil
+}
+
+// indexOutsideQuotes returns the index of the first occurrence of sub outside quoted strings
+func indexOutsideQuotes(s, sub string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.HasPrefix(s[i:], sub):
+			return i
+		}
+	}
+	return -1
+}
+
+// isQuoted reports whether s is enclosed in single or double quotes
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]
+}
+
+// optionalInt parses a slice bound, which may be empty
+func optionalInt(s string) (*int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slice bound %q", s)
+	}
+	return &value, nil
+}
@@ -0,0 +1,103 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDocument = `{
+	"store": {
+		"books": [
+			{"title": "Go", "price": 30, "tags": ["programming"]},
+			{"title": "Rust", "price": 45},
+			{"title": "Poems", "price": 12, "author": "Anon"}
+		],
+		"owner": {"name": "Ada"}
+	}
+}`
+
+func TestPath_Get(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(testDocument), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr  string
+		want  interface{}
+		found bool
+	}{
+		{"$.store.owner.name", "Ada", true},
+		{"$['store']['owner']['name']", "Ada", true},
+		{"$.store.books[0].title", "Go", true},
+		{"$.store.books[-1].title", "Poems", true},
+		{"$.store.books[5].title", nil, false},
+		{"$.store.missing", nil, false},
+		{"$.store.books[*].price", []interface{}{30.0, 45.0, 12.0}, true},
+		{"$.store.books[1:].title", []interface{}{"Rust", "Poems"}, true},
+		{"$..title", []interface{}{"Go", "Rust", "Poems"}, true},
+		{"$.store.books[?(@.price < 40)].title", []interface{}{"Go", "Poems"}, true},
+		{"$.store.books[?(@.title == 'Rust')].price", []interface{}{45.0}, true},
+		{"$.store.books[?(@.author)].title", []interface{}{"Poems"}, true},
+		{"$.store.books[?(@.price > 100)]", []interface{}{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			got, found := path.Get(doc)
+			if found != tt.found {
+				t.Errorf("found = %v, want %v", found, tt.found)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPath_GetNumbers(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(`[{"id": 9007199254740993}, {"id": 9007199254740992}, {"id": 1.5}]`))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{"$[?(@.id == 9007199254740993)].id", []interface{}{json.Number("9007199254740993")}},
+		{"$[?(@.id > 9007199254740992)].id", []interface{}{json.Number("9007199254740993")}},
+		{"$[?(@.id != 9007199254740992)].id", []interface{}{json.Number("9007199254740993"), json.Number("1.5")}},
+		{"$[?(@.id == 1.50)].id", []interface{}{json.Number("1.5")}},
+		{"$[?(@.id < 2)].id", []interface{}{json.Number("1.5")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got, _ := path.Get(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, expr := range []string{"store.name", "$.", "$[", "$[abc]", "$[?(price > 1)]", "$x"} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q) expected error", expr)
+		}
+	}
+}
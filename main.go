@@ -21,6 +21,28 @@ func main() {
 		toolsDir = "./tools"
 	}
 
+	modelsDir := os.Getenv("GIN_MCP_MODELS_DIR")
+	if modelsDir == "" {
+		modelsDir = "./models"
+	}
+
+	// Jobs are kept in memory unless a directory is configured
+	jobsDir := os.Getenv("GIN_MCP_JOBS_DIR")
+
+	// Secrets that tool manifests can request
+	secretsFile := os.Getenv("GIN_MCP_SECRETS_FILE")
+	secretsEnvPrefix := os.Getenv("GIN_MCP_SECRETS_ENV_PREFIX")
+
+	// Pre-built virtualenvs of Python tools with dependencies
+	pythonEnvsDir := os.Getenv("GIN_MCP_PYTHON_ENVS_DIR")
+
+	// Database that .sql resources run against
+	databaseDriver := os.Getenv("GIN_MCP_DATABASE_DRIVER")
+	databaseDSN := os.Getenv("GIN_MCP_DATABASE_DSN")
+
+	// Prefix of resource URIs, e.g. mcp://project/
+	resourceURIBase := os.Getenv("GIN_MCP_RESOURCE_URI_BASE")
+
 	port := os.Getenv("GIN_MCP_PORT")
 	if port == "" {
 		port = ":8080"
@@ -30,8 +52,20 @@ func main() {
 	config := &ginmcp.MCPConfig{
 		ResourcesDir: resourcesDir,
 		ToolsDir:     toolsDir,
+		ModelsDir:    modelsDir,
+		JobsDir:      jobsDir,
 		Prefix:       "/mcp",
 		Port:         port,
+
+		SecretsFile:      secretsFile,
+		SecretsEnvPrefix: secretsEnvPrefix,
+
+		PythonEnvsDir: pythonEnvsDir,
+
+		DatabaseDriver: databaseDriver,
+		DatabaseDSN:    databaseDSN,
+
+		ResourceURIBase: resourceURIBase,
 	}
 
 	mcp, err := ginmcp.New(config)
@@ -54,6 +88,7 @@ func main() {
 	log.Printf("🚀 Starting gin-mcp MCP server on port %s", port)
 	log.Printf("📁 Watching resources directory: %s", resourcesDir)
 	log.Printf("🔧 Watching tools directory: %s", toolsDir)
+	log.Printf("🧠 Watching models directory: %s", modelsDir)
 	log.Printf("🔌 MCP Protocol Version: 2025.06.18")
 
 	if err := mcp.StartStandalone(); err != nil {
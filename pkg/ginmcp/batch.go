@@ -0,0 +1,176 @@
+package ginmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchRequest is the body of a batch tool call or prediction
+type batchRequest struct {
+	Items       []json.RawMessage `json:"items"`       // Argument objects for tools, inputs for models
+	Concurrency int               `json:"concurrency"` // Parallel executions (default and maximum: MaxBatchConcurrency)
+	Stream      bool              `json:"stream"`      // Stream results as NDJSON as they complete
+}
+
+// batchResult is the outcome of a single batch item
+type batchResult struct {
+	Index  int             `json:"index"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// batchToolHandler executes a tool once per item of the batch
+func (m *MCP) batchToolHandler(c *gin.Context) {
+	toolName := nameParam(c)
+
+	tool, exists := m.registry.GetTool(toolName)
+	if !exists {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Tool '%s' not found", toolName),
+		})
+		return
+	}
+
+	m.serveBatch(c, func(ctx context.Context, item json.RawMessage) ([]byte, error) {
+		input, err := json.Marshal(map[string]json.RawMessage{"arguments": item})
+		if err != nil {
+			return nil, err
+		}
+		return m.handler.ExecuteToolContext(ctx, tool, input)
+	})
+}
+
+// batchPredictHandler runs a model once per item of the batch
+func (m *MCP) batchPredictHandler(c *gin.Context) {
+	modelName := nameParam(c)
+
+	model, exists := m.registry.GetModel(modelName)
+	if !exists {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Model '%s' not found", modelName),
+		})
+		return
+	}
+
+	m.serveBatch(c, func(ctx context.Context, item json.RawMessage) ([]byte, error) {
+		return m.handler.Predict(ctx, model, item)
+	})
+}
+
+// serveBatch parses a batch request, runs every item with bounded parallelism
+// and writes the results in order, or as NDJSON in completion order when streaming
+func (m *MCP) serveBatch(c *gin.Context, execute func(ctx context.Context, item json.RawMessage) ([]byte, error)) {
+	var request batchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{
+			"error": fmt.Sprintf("Invalid batch request: %v", err),
+		})
+		return
+	}
+
+	if len(request.Items) == 0 {
+		c.JSON(400, gin.H{
+			"error": "Batch request must contain at least one item",
+		})
+		return
+	}
+	if len(request.Items) > m.config.MaxBatchItems {
+		c.JSON(413, gin.H{
+			"error": fmt.Sprintf("Batch request exceeds the maximum of %d items", m.config.MaxBatchItems),
+		})
+		return
+	}
+
+	concurrency := request.Concurrency
+	if concurrency <= 0 || concurrency > m.config.MaxBatchConcurrency {
+		concurrency = m.config.MaxBatchConcurrency
+	}
+
+	if c.Query("stream") == "true" {
+		request.Stream = true
+	}
+
+	results := runBatch(c.Request.Context(), request.Items, concurrency, execute)
+
+	if request.Stream {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(200)
+
+		encoder := json.NewEncoder(c.Writer)
+		for result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		return
+	}
+
+	ordered := make([]batchResult, len(request.Items))
+	failed := 0
+	for result := range results {
+		ordered[result.Index] = result
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	c.JSON(200, gin.H{
+		"results": ordered,
+		"count":   len(ordered),
+		"errors":  failed,
+	})
+}
+
+// runBatch executes items with at most concurrency parallel executions. Results
+// are sent in completion order and the channel is closed once all items are done.
+func runBatch(ctx context.Context, items []json.RawMessage, concurrency int, execute func(ctx context.Context, item json.RawMessage) ([]byte, error)) <-chan batchResult {
+	results := make(chan batchResult, len(items))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(items); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				result := batchResult{Index: index}
+
+				output, err := execute(ctx, items[index])
+				if err != nil {
+					result.Error = err.Error()
+				} else if json.Valid(output) {
+					result.Result = output
+				} else {
+					result.Error = "invalid JSON output"
+				}
+
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		defer close(indexes)
+
+		for index := range items {
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				// Report the items that were never started
+				for ; index < len(items); index++ {
+					results <- batchResult{Index: index, Error: ctx.Err().Error()}
+				}
+				return
+			}
+		}
+	}()
+
+	return results
+}
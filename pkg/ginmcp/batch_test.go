@@ -0,0 +1,129 @@
+package ginmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type squareArgs struct {
+	N int `json:"n"`
+}
+
+type squareResult struct {
+	Square int `json:"square"`
+}
+
+// newTestMCP creates an MCP server with its routes on a fresh engine
+func newTestMCP(t *testing.T, config *MCPConfig) (*MCP, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ResourcesDir = filepath.Join(dir, "resources")
+	config.ToolsDir = filepath.Join(dir, "tools")
+	config.ModelsDir = ""
+
+	mcp, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	if err := mcp.SetupRoutes(router); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mcp.Stop() })
+
+	return mcp, router
+}
+
+func TestMCP_BatchTool(t *testing.T) {
+	mcp, router := newTestMCP(t, nil)
+
+	err := mcp.RegisterFunc("square", "Square a number", func(ctx context.Context, args squareArgs) (squareResult, error) {
+		if args.N < 0 {
+			return squareResult{}, errors.New("negative input")
+		}
+		return squareResult{Square: args.N * args.N}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"items": [{"n": 1}, {"n": 2}, {"n": 3}, {"n": -1}], "concurrency": 2}`
+
+	t.Run("ordered results", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/batch/tools/square", strings.NewReader(body)))
+
+		if recorder.Code != 200 {
+			t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+		}
+
+		var response struct {
+			Results []struct {
+				Index  int `json:"index"`
+				Result struct {
+					IsError           bool         `json:"isError"`
+					StructuredContent squareResult `json:"structuredContent"`
+				} `json:"result"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(response.Results) != 4 {
+			t.Fatalf("got %d results, want 4", len(response.Results))
+		}
+		for i, want := range []int{1, 4, 9} {
+			result := response.Results[i]
+			if result.Index != i || result.Result.StructuredContent.Square != want {
+				t.Errorf("results[%d] = %+v, want square %d", i, result, want)
+			}
+		}
+		if !response.Results[3].Result.IsError {
+			t.Errorf("Expected the negative input to produce an error result")
+		}
+	})
+
+	t.Run("streamed results", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/batch/tools/square?stream=true", strings.NewReader(body)))
+
+		if contentType := recorder.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+			t.Errorf("Content-Type = %s, want application/x-ndjson", contentType)
+		}
+
+		seen := map[int]bool{}
+		scanner := bufio.NewScanner(recorder.Body)
+		for scanner.Scan() {
+			var result batchResult
+			if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+				t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+			}
+			seen[result.Index] = true
+		}
+		if len(seen) != 4 {
+			t.Errorf("streamed %d distinct results, want 4", len(seen))
+		}
+	})
+
+	t.Run("unknown tool", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/batch/tools/missing", strings.NewReader(body)))
+		if recorder.Code != 404 {
+			t.Errorf("status = %d, want 404", recorder.Code)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package ginmcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Wednesday
+	base := time.Date(2025, time.January, 15, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 8 * * sun", time.Date(2025, 1, 19, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2025, 1, 15, 10, 32, 15, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCron() error = %v", err)
+			}
+			if got := schedule.next(base); !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every 1ms"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ginmcp
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gin-mcp/handlers"
+
+	// SQLite is the default driver of SQL resources
+	_ "modernc.org/sqlite"
+)
+
+// DefaultDatabaseDriver runs SQL resources when no other driver is configured
+const DefaultDatabaseDriver = "sqlite"
+
+// openDatabase connects to the database of SQL resources and configures the handler
+func openDatabase(config *MCPConfig, handler *handlers.MCPHandler) (*sql.DB, error) {
+	driver := config.DatabaseDriver
+	if driver == "" {
+		driver = DefaultDatabaseDriver
+	}
+
+	dsn := config.DatabaseDSN
+	if driver == DefaultDatabaseDriver && !config.DatabaseAllowWrites {
+		// Let SQLite itself refuse writes on every connection
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_pragma=query_only(1)"
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", driver, err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s database: %w", driver, err)
+	}
+
+	handler.SetDatabase(db, handlers.DatabaseSettings{
+		MaxRows:     config.DatabaseMaxRows,
+		Timeout:     config.DatabaseTimeout,
+		AllowWrites: config.DatabaseAllowWrites,
+		Placeholder: placeholderFor(driver),
+	})
+	return db, nil
+}
+
+// placeholderFor returns the placeholder style of well-known drivers
+func placeholderFor(driver string) string {
+	switch driver {
+	case "postgres", "pgx", "cloudsqlpostgres":
+		return handlers.PlaceholderDollar
+	case "sqlserver", "mssql":
+		return handlers.PlaceholderAtP
+	default:
+		return handlers.PlaceholderQuestion
+	}
+}
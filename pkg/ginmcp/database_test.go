@@ -0,0 +1,22 @@
+package ginmcp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gin-mcp/handlers"
+)
+
+func TestOpenDatabase_ReadOnly(t *testing.T) {
+	config := &MCPConfig{DatabaseDSN: filepath.Join(t.TempDir(), "test.db")}
+
+	db, err := openDatabase(config, handlers.NewMCPHandler())
+	if err != nil {
+		t.Fatalf("openDatabase() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE notes (text TEXT)"); err == nil {
+		t.Error("Expected a read-only SQLite database to reject writes")
+	}
+}
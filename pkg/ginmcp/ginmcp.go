@@ -1,12 +1,18 @@
 package ginmcp
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"gin-mcp/handlers"
+	"gin-mcp/jobs"
 	"gin-mcp/registry"
+	"gin-mcp/search"
 	"gin-mcp/watcher"
 
 	"github.com/gin-gonic/gin"
@@ -16,26 +22,82 @@ import (
 type MCPConfig struct {
 	ResourcesDir string // Directory to watch for MCP resources
 	ToolsDir     string // Directory to watch for MCP tools
+	ModelsDir    string // Directory to watch for models (empty disables models)
 	Prefix       string // URL prefix for MCP endpoints (default: "/mcp")
 	Port         string // Port for the MCP server (if standalone)
+
+	PanicThreshold int // Consecutive panics before an in-process tool is quarantined (default: 3)
+
+	MaxBatchConcurrency int // Maximum parallel executions per batch request (default: 8)
+	MaxBatchItems       int // Maximum items per batch request (default: 1000)
+
+	JobTimeout   time.Duration // Maximum run time of an asynchronous job (default: 1h)
+	JobRetention time.Duration // How long finished jobs and their results are kept (default: 24h)
+	JobsDir      string        // Directory to persist jobs in (empty keeps jobs in memory only)
+
+	Schedules []Schedule // Tools to run periodically, in addition to schedules from tool manifests
+
+	CacheMaxEntries int   // Maximum cached tool results (default: 1000)
+	CacheMaxBytes   int64 // Maximum total size of cached tool results (default: 64 MiB)
+
+	ToolEnvAllowlist []string          // Server environment variables passed to subprocess tools (nil passes all)
+	ToolEnv          map[string]string // Additional environment variables for subprocess tools
+	ToolWorkingDir   string            // Working directory of subprocess tools (empty uses the server's)
+	SecretsFile      string            // KEY=VALUE file with secrets that tool manifests can request
+	SecretsEnvPrefix string            // Prefix of server environment variables holding secrets, e.g. "GIN_MCP_SECRET_"
+
+	ToolSandbox registry.SandboxSettings // Linux isolation of subprocess tools, tool manifests can enable and tighten it
+
+	PythonEnvsDir string // Pre-built virtualenvs of Python tools that declare dependencies (empty uses the tool's manifest or python3)
+
+	DatabaseDriver      string        // database/sql driver of SQL resources (default: "sqlite"), other drivers must be imported by the application
+	DatabaseDSN         string        // Data source SQL resources run against (empty returns .sql resources as text)
+	DatabaseMaxRows     int           // Maximum rows returned per query (default: 1000)
+	DatabaseTimeout     time.Duration // Query timeout (default: 30s)
+	DatabaseAllowWrites bool          // Allow statements other than queries
+
+	MaxInlineResourceBytes int64  // Largest resource returned inline as text or base64 blob (default: 10 MiB)
+	ResourceURIBase        string // Prefix of resource URIs, followed by the path below ResourcesDir (default: "file:///")
+
+	DisableResourceSearch bool // Leave out the full-text index of resources, the search endpoint and the search_resources tool
 }
 
+const (
+	defaultMaxBatchConcurrency = 8
+	defaultMaxBatchItems       = 1000
+)
+
 // DefaultConfig returns default configuration
 func DefaultConfig() *MCPConfig {
 	return &MCPConfig{
 		ResourcesDir: "./resources",
 		ToolsDir:     "./tools",
+		ModelsDir:    "./models",
 		Prefix:       "/mcp",
 		Port:         ":8080",
+
+		PanicThreshold: handlers.DefaultPanicThreshold,
+
+		MaxBatchConcurrency: defaultMaxBatchConcurrency,
+		MaxBatchItems:       defaultMaxBatchItems,
+
+		CacheMaxEntries: handlers.DefaultCacheMaxEntries,
+		CacheMaxBytes:   handlers.DefaultCacheMaxBytes,
 	}
 }
 
 // MCP represents the MCP server
 type MCP struct {
-	config   *MCPConfig
-	registry *registry.Registry
-	handler  *handlers.MCPHandler
-	watcher  *watcher.Watcher
+	config    *MCPConfig
+	registry  *registry.Registry
+	handler   *handlers.MCPHandler
+	watcher   *watcher.Watcher
+	jobs      *jobs.Manager
+	scheduler *scheduler
+	poller    *resourcePoller
+	engine    *gin.Engine
+	database  *sql.DB
+	search    *search.Index // Full-text index of resources, nil when disabled
 }
 
 // New creates a new MCP server instance
@@ -44,11 +106,85 @@ func New(config *MCPConfig) (*MCP, error) {
 		config = DefaultConfig()
 	}
 
-	return &MCP{
-		config:   config,
-		registry: registry.NewRegistry(),
-		handler:  handlers.NewMCPHandler(),
-	}, nil
+	if config.MaxBatchConcurrency <= 0 {
+		config.MaxBatchConcurrency = defaultMaxBatchConcurrency
+	}
+	if config.MaxBatchItems <= 0 {
+		config.MaxBatchItems = defaultMaxBatchItems
+	}
+
+	handler := handlers.NewMCPHandler()
+	handler.SetPanicThreshold(config.PanicThreshold)
+	handler.SetCacheLimits(config.CacheMaxEntries, config.CacheMaxBytes)
+	handler.SetMaxInlineSize(config.MaxInlineResourceBytes)
+	handler.SetSubprocessSettings(handlers.SubprocessSettings{
+		EnvAllowlist: config.ToolEnvAllowlist,
+		Env:          config.ToolEnv,
+		WorkingDir:   config.ToolWorkingDir,
+		Sandbox:      config.ToolSandbox,
+	})
+
+	secrets, err := handlers.NewSecrets(config.SecretsFile, config.SecretsEnvPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+	handler.SetSecrets(secrets)
+
+	if err := config.ToolSandbox.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tool sandbox: %w", err)
+	}
+
+	reg := registry.NewRegistry()
+	if err := reg.SetResourceURIs(config.ResourcesDir, config.ResourceURIBase); err != nil {
+		return nil, err
+	}
+
+	var database *sql.DB
+	if config.DatabaseDSN != "" {
+		if database, err = openDatabase(config, handler); err != nil {
+			return nil, err
+		}
+	}
+
+	jobManager, err := jobs.NewManager(jobs.Config{
+		Timeout:   config.JobTimeout,
+		Retention: config.JobRetention,
+		Dir:       config.JobsDir,
+	})
+	if err != nil {
+		if database != nil {
+			database.Close()
+		}
+		return nil, fmt.Errorf("failed to create job manager: %w", err)
+	}
+
+	reg.SetPythonEnvsDir(config.PythonEnvsDir)
+	reg.SetSecretLookup(secrets.Lookup)
+	handler.SetRegistry(reg)
+
+	m := &MCP{
+		config:    config,
+		registry:  reg,
+		handler:   handler,
+		jobs:      jobManager,
+		scheduler: newScheduler(config.Schedules, reg, handler),
+		poller:    newResourcePoller(reg, handler),
+		database:  database,
+	}
+
+	if !config.DisableResourceSearch {
+		m.search = search.NewIndex()
+		reg.OnResourceChange(m.indexResource)
+		if err := m.registerSearchTool(); err != nil {
+			jobManager.Stop()
+			if database != nil {
+				database.Close()
+			}
+			return nil, fmt.Errorf("failed to register search tool: %w", err)
+		}
+	}
+
+	return m, nil
 }
 
 // SetupRoutes adds MCP server routes to an existing Gin router
@@ -58,21 +194,59 @@ func (m *MCP) SetupRoutes(router *gin.Engine) error {
 		return fmt.Errorf("failed to initialize watcher: %w", err)
 	}
 
+	m.engine = router
+
+	// Start running scheduled tools and polling API resources once the existing files are registered
+	m.scheduler.start()
+	m.poller.start()
+
 	// Create MCP route group
 	mcpGroup := router.Group(m.config.Prefix)
+	mcpGroup.Use(sessionMiddleware)
 
 	// Health check endpoint
 	mcpGroup.GET("/health", m.healthHandler)
 
 	// MCP Resources endpoints
 	mcpGroup.GET("/resources", m.listResourcesHandler)
-	mcpGroup.GET("/resources/:name", m.getResourceInfoHandler)
-	mcpGroup.POST("/resources/:name", m.accessResourceHandler)
+	mcpGroup.POST("/resources", m.readResourceHandler)
+	mcpGroup.GET("/resources/*name", m.getResourceInfoHandler)
+	mcpGroup.POST("/resources/*name", m.accessResourceHandler)
+
+	// Full-text search of resources
+	if m.search != nil {
+		mcpGroup.GET("/search", m.searchHandler)
+	}
 
 	// MCP Tools endpoints
 	mcpGroup.GET("/tools", m.listToolsHandler)
-	mcpGroup.GET("/tools/:name", m.getToolInfoHandler)
-	mcpGroup.POST("/tools/:name", m.executeToolHandler)
+	mcpGroup.GET("/tools/*name", m.getToolInfoHandler)
+	mcpGroup.POST("/tools/*name", m.executeToolHandler)
+
+	// Model endpoints
+	if m.config.ModelsDir != "" {
+		mcpGroup.GET("/models", m.listModelsHandler)
+		mcpGroup.GET("/models/*name", m.getModelInfoHandler)
+		mcpGroup.POST("/models/*name", m.predictHandler)
+	}
+
+	// Batch endpoints
+	mcpGroup.POST("/batch/tools/*name", m.batchToolHandler)
+	if m.config.ModelsDir != "" {
+		mcpGroup.POST("/batch/models/*name", m.batchPredictHandler)
+	}
+
+	// Asynchronous job endpoints
+	mcpGroup.GET("/jobs", m.listJobsHandler)
+	mcpGroup.GET("/jobs/:id", m.getJobHandler)
+	mcpGroup.POST("/jobs/:id/cancel", m.cancelJobHandler)
+	mcpGroup.DELETE("/jobs/:id", m.deleteJobHandler)
+
+	// Scheduled tool runs
+	mcpGroup.GET("/schedules", m.listSchedulesHandler)
+
+	// Server notifications such as resource updates
+	mcpGroup.GET("/notifications", m.notificationsHandler)
 
 	// Registry export endpoint (for debugging)
 	mcpGroup.GET("/registry", m.exportRegistryHandler)
@@ -100,6 +274,16 @@ func (m *MCP) StartStandalone() error {
 
 // Stop gracefully shuts down the MCP server
 func (m *MCP) Stop() error {
+	m.scheduler.stop()
+	m.poller.stop()
+	m.jobs.Stop()
+
+	if m.database != nil {
+		if err := m.database.Close(); err != nil {
+			log.Printf("⚠️  Failed to close database: %v", err)
+		}
+	}
+
 	if m.watcher != nil {
 		return m.watcher.Stop()
 	}
@@ -116,12 +300,20 @@ func (m *MCP) GetHandler() *handlers.MCPHandler {
 	return m.handler
 }
 
-// initializeWatcher sets up the file watcher for both resources and tools
+// RegisterFunc registers a typed Go function with the signature
+// func(context.Context, Args) (Result, error) as an in-process tool, listed
+// alongside file-discovered tools
+func (m *MCP) RegisterFunc(name, description string, fn interface{}) error {
+	return m.registry.RegisterFunc(name, description, fn)
+}
+
+// initializeWatcher sets up the file watcher for resources, tools and models
 func (m *MCP) initializeWatcher() error {
 	watcher, err := watcher.NewWatcher(m.config.ResourcesDir, m.config.ToolsDir, m.registry)
 	if err != nil {
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
+	watcher.SetModelsDir(m.config.ModelsDir)
 
 	m.watcher = watcher
 
@@ -140,23 +332,42 @@ func (m *MCP) healthHandler(c *gin.Context) {
 		"mcp_version": "2025.06.18",
 		"resources":   m.registry.GetResourceCount(),
 		"tools":       m.registry.GetToolCount(),
+		"models":      m.registry.GetModelCount(),
 		"watcher":     m.watcher.IsRunning(),
 		"prefix":      m.config.Prefix,
+		"tool_panics": m.handler.PanicStats(),
+		"jobs":        m.jobs.Count(),
+		"schedules":   m.scheduleSummary(),
+		"cache":       m.cacheSummary(),
 	})
 }
 
+// cacheSummary returns the result cache counters in total and per tool
+func (m *MCP) cacheSummary() gin.H {
+	total, perTool := m.handler.CacheStats()
+	return gin.H{
+		"hits":      total.Hits,
+		"misses":    total.Misses,
+		"evictions": total.Evictions,
+		"entries":   total.Entries,
+		"bytes":     total.Bytes,
+		"tools":     perTool,
+	}
+}
+
+// nameParam returns the name of the addressed resource, tool or model. Names of
+// items in subdirectories contain slashes, so routes capture the rest of the path.
+func nameParam(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("name"), "/")
+}
+
 // listResourcesHandler returns a list of all available MCP resources
 func (m *MCP) listResourcesHandler(c *gin.Context) {
 	resources := m.registry.ListResources()
 
 	resourceList := make([]gin.H, len(resources))
 	for i, resource := range resources {
-		resourceList[i] = gin.H{
-			"name":      resource.Name,
-			"type":      resource.Type,
-			"file_path": resource.FilePath,
-			"mime_type": resource.MimeType,
-		}
+		resourceList[i] = resourceSummary(resource)
 	}
 
 	c.JSON(200, gin.H{
@@ -167,7 +378,7 @@ func (m *MCP) listResourcesHandler(c *gin.Context) {
 
 // getResourceInfoHandler returns information about a specific MCP resource
 func (m *MCP) getResourceInfoHandler(c *gin.Context) {
-	resourceName := c.Param("name")
+	resourceName := nameParam(c)
 
 	resource, exists := m.registry.GetResource(resourceName)
 	if !exists {
@@ -177,17 +388,37 @@ func (m *MCP) getResourceInfoHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, gin.H{
+	c.JSON(200, resourceSummary(resource))
+}
+
+// resourceSummary describes a resource in listings, with the title,
+// description, size and annotations it has. The file path is left out, the
+// name and URI identify the resource without revealing the server layout.
+func resourceSummary(resource *registry.ResourceInfo) gin.H {
+	summary := gin.H{
 		"name":      resource.Name,
+		"uri":       resource.URI,
 		"type":      resource.Type,
-		"file_path": resource.FilePath,
 		"mime_type": resource.MimeType,
-	})
+	}
+	if resource.Title != "" {
+		summary["title"] = resource.Title
+	}
+	if resource.Description != "" {
+		summary["description"] = resource.Description
+	}
+	if resource.Size > 0 {
+		summary["size"] = resource.Size
+	}
+	if resource.Annotations != nil {
+		summary["annotations"] = resource.Annotations
+	}
+	return summary
 }
 
 // accessResourceHandler accesses MCP resource content
 func (m *MCP) accessResourceHandler(c *gin.Context) {
-	resourceName := c.Param("name")
+	resourceName := nameParam(c)
 
 	// Get the resource from registry
 	resource, exists := m.registry.GetResource(resourceName)
@@ -207,8 +438,68 @@ func (m *MCP) accessResourceHandler(c *gin.Context) {
 		return
 	}
 
-	// Access the resource
-	result, err := m.handler.AccessResource(resource, body)
+	m.accessResource(c, resource, body)
+}
+
+// readResourceHandler reads a resource addressed by the "uri" or "name" of the
+// request body. The whole body is passed on as the access request.
+func (m *MCP) readResourceHandler(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": fmt.Sprintf("Failed to read request body: %v", err),
+		})
+		return
+	}
+
+	var request struct {
+		URI  string `json:"uri"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || (request.URI == "" && request.Name == "") {
+		c.JSON(400, gin.H{
+			"error": "Request body must be a JSON object with the uri or name of a resource",
+		})
+		return
+	}
+
+	reference := request.URI
+	resource, exists := m.registry.GetResourceByURI(request.URI)
+	if request.URI == "" {
+		reference = request.Name
+		resource, exists = m.registry.GetResource(request.Name)
+	}
+	if !exists {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Resource '%s' not found", reference),
+		})
+		return
+	}
+
+	m.accessResource(c, resource, body)
+}
+
+// accessResource responds with the content of a resource
+func (m *MCP) accessResource(c *gin.Context, resource *registry.ResourceInfo, body []byte) {
+	result, err := m.handler.AccessResourceContext(c.Request.Context(), resource, body)
+	if errors.Is(err, handlers.ErrAccessDenied) {
+		c.JSON(403, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, handlers.ErrResourceTooLarge) {
+		c.JSON(413, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, handlers.ErrInvalidRange) || errors.Is(err, handlers.ErrInvalidQuery) || errors.Is(err, handlers.ErrUnsupportedFormat) {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{
 			"error": fmt.Sprintf("Resource access failed: %v", err),
@@ -223,7 +514,7 @@ func (m *MCP) accessResourceHandler(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"contents": []gin.H{
 				{
-					"uri":       fmt.Sprintf("file://%s", resource.FilePath),
+					"uri":       resource.URI,
 					"mime_type": resource.MimeType,
 					"text":      string(result),
 				},
@@ -248,6 +539,15 @@ func (m *MCP) listToolsHandler(c *gin.Context) {
 			"file_path":    tool.FilePath,
 			"input_schema": tool.InputSchema,
 		}
+		if tool.OutputSchema != nil {
+			toolList[i]["output_schema"] = tool.OutputSchema
+		}
+		if tool.Status != "" {
+			toolList[i]["status"] = tool.Status
+		}
+		if tool.StatusError != "" {
+			toolList[i]["status_error"] = tool.StatusError
+		}
 	}
 
 	c.JSON(200, gin.H{
@@ -258,7 +558,7 @@ func (m *MCP) listToolsHandler(c *gin.Context) {
 
 // getToolInfoHandler returns information about a specific MCP tool
 func (m *MCP) getToolInfoHandler(c *gin.Context) {
-	toolName := c.Param("name")
+	toolName := nameParam(c)
 
 	tool, exists := m.registry.GetTool(toolName)
 	if !exists {
@@ -268,18 +568,32 @@ func (m *MCP) getToolInfoHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, gin.H{
+	toolData := gin.H{
 		"name":         tool.Name,
 		"description":  tool.Description,
 		"type":         tool.Type,
 		"file_path":    tool.FilePath,
 		"input_schema": tool.InputSchema,
-	})
+	}
+	if tool.OutputSchema != nil {
+		toolData["output_schema"] = tool.OutputSchema
+	}
+	if tool.Status != "" {
+		toolData["status"] = tool.Status
+	}
+	if tool.StatusError != "" {
+		toolData["status_error"] = tool.StatusError
+	}
+	if tool.Python != nil {
+		toolData["python"] = tool.Python
+	}
+
+	c.JSON(200, toolData)
 }
 
 // executeToolHandler executes an MCP tool with the provided input
 func (m *MCP) executeToolHandler(c *gin.Context) {
-	toolName := c.Param("name")
+	toolName := nameParam(c)
 
 	// Get the tool from registry
 	tool, exists := m.registry.GetTool(toolName)
@@ -299,12 +613,16 @@ func (m *MCP) executeToolHandler(c *gin.Context) {
 		return
 	}
 
+	// Run the tool as a background job when requested
+	if c.Query("async") == "true" {
+		m.submitToolJob(c, tool, body)
+		return
+	}
+
 	// Execute the tool
-	result, err := m.handler.ExecuteTool(tool, body)
+	result, err := m.handler.ExecuteToolContext(c.Request.Context(), tool, body)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"error": fmt.Sprintf("Tool execution failed: %v", err),
-		})
+		respondToolError(c, toolName, err)
 		return
 	}
 
@@ -326,6 +644,24 @@ func (m *MCP) executeToolHandler(c *gin.Context) {
 	c.JSON(200, jsonResult)
 }
 
+// respondToolError responds with the status matching a failed tool call
+func respondToolError(c *gin.Context, toolName string, err error) {
+	switch {
+	case errors.Is(err, handlers.ErrToolQuarantined):
+		c.JSON(503, gin.H{
+			"error": fmt.Sprintf("Tool '%s' is quarantined after repeated panics", toolName),
+		})
+	case errors.Is(err, handlers.ErrAccessDenied):
+		c.JSON(403, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(500, gin.H{
+			"error": fmt.Sprintf("Tool execution failed: %v", err),
+		})
+	}
+}
+
 // exportRegistryHandler exports the registry for debugging
 func (m *MCP) exportRegistryHandler(c *gin.Context) {
 	registryData, err := m.registry.ExportRegistry()
@@ -0,0 +1,49 @@
+package ginmcp
+
+import (
+	"context"
+
+	"gin-mcp/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHeader carries the MCP session id of a client. The client chooses
+// it, so it correlates calls but does not authenticate them.
+const SessionHeader = "Mcp-Session-Id"
+
+// Interceptor runs around tool calls and resource reads. See handlers.Interceptor.
+type Interceptor = handlers.Interceptor
+
+// Call describes an intercepted tool call or resource read
+type Call = handlers.Call
+
+// Invoker continues an intercepted call with the rest of the chain
+type Invoker = handlers.Invoker
+
+// Use adds interceptors that run, in order, around every tool call and resource
+// read, including batch items, asynchronous jobs, pipeline steps and scheduled
+// runs. Returning an error wrapping handlers.ErrAccessDenied responds with 403.
+func (m *MCP) Use(interceptors ...Interceptor) {
+	m.handler.Use(interceptors...)
+}
+
+// WithSession returns a context carrying the session of a request. Host
+// authentication middleware that runs before the MCP routes can set a verified
+// identity this way, which takes precedence over the Mcp-Session-Id header.
+func WithSession(ctx context.Context, session string) context.Context {
+	return handlers.WithSession(ctx, session)
+}
+
+// sessionMiddleware makes the client session available to interceptors and
+// jobs, unless host middleware already set one
+func sessionMiddleware(c *gin.Context) {
+	if handlers.SessionFromContext(c.Request.Context()) != "" {
+		c.Next()
+		return
+	}
+	if session := c.GetHeader(SessionHeader); session != "" {
+		c.Request = c.Request.WithContext(handlers.WithSession(c.Request.Context(), session))
+	}
+	c.Next()
+}
@@ -0,0 +1,91 @@
+package ginmcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-mcp/handlers"
+)
+
+func TestMCP_Use(t *testing.T) {
+	mcp, router := newTestMCP(t, nil)
+
+	err := mcp.RegisterFunc("square", "Square a number", func(ctx context.Context, args squareArgs) (squareResult, error) {
+		return squareResult{Square: args.N * args.N}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var audit []string
+	mcp.Use(
+		// Authorization and auditing
+		func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			if call.Session != "trusted" {
+				return nil, fmt.Errorf("%w: unknown session", handlers.ErrAccessDenied)
+			}
+			audit = append(audit, call.Tool.Name)
+			return next(ctx, call)
+		},
+		// Short-circuit with a canned result
+		func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			if bytes.Contains(call.Input, []byte(`"n": 0`)) {
+				return []byte(`{"content": [{"type": "text", "text": "zero"}]}`), nil
+			}
+			return next(ctx, call)
+		},
+		// Input and output rewriting
+		func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			call.Input = bytes.Replace(call.Input, []byte(`"n": 2`), []byte(`"n": 3`), 1)
+			output, err := next(ctx, call)
+			if err != nil {
+				return nil, err
+			}
+			return bytes.Replace(output, []byte(`"square":9`), []byte(`"square":9,"rewritten":true`), 1), nil
+		},
+	)
+
+	tests := []struct {
+		name    string
+		session string
+		body    string
+		status  int
+		want    string
+	}{
+		{"denied without session", "", `{"arguments": {"n": 2}}`, 403, "access denied"},
+		{"input and output rewritten", "trusted", `{"arguments": {"n": 2}}`, 200, `"rewritten":true`},
+		{"short-circuited", "trusted", `{"arguments": {"n": 0}}`, 200, `"text":"zero"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/mcp/tools/square", strings.NewReader(tt.body))
+			if tt.session != "" {
+				request.Header.Set(SessionHeader, tt.session)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", recorder.Code, tt.status, recorder.Body.String())
+			}
+
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, recorder.Body.Bytes()); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(compact.String(), tt.want) {
+				t.Errorf("body = %s, want it to contain %s", compact.String(), tt.want)
+			}
+		})
+	}
+
+	if strings.Join(audit, ",") != "square,square" {
+		t.Errorf("audit = %v", audit)
+	}
+}
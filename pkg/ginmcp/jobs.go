@@ -0,0 +1,124 @@
+package ginmcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gin-mcp/handlers"
+	"gin-mcp/jobs"
+	"gin-mcp/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// submitToolJob runs a tool as an asynchronous job and responds with its id.
+// The interceptors run before the job is submitted, so that a rejected call
+// fails the request rather than the job.
+func (m *MCP) submitToolJob(c *gin.Context, tool *registry.ToolInfo, body []byte) {
+	// The job outlives the request, so its cancellation is not carried over
+	ctx := context.WithoutCancel(c.Request.Context())
+	session := handlers.SessionFromContext(ctx)
+
+	run, output, err := m.handler.PrepareToolCall(ctx, tool, body)
+	if err != nil {
+		respondToolError(c, tool.Name, err)
+		return
+	}
+	if run == nil {
+		// An interceptor answered without invoking the tool
+		run = func(ctx context.Context) ([]byte, error) {
+			return output, nil
+		}
+	}
+
+	job := m.jobs.Submit(tool.Name, session, run)
+
+	c.JSON(202, gin.H{
+		"job_id":     job.ID,
+		"status":     job.Status,
+		"status_url": fmt.Sprintf("%s/jobs/%s", m.config.Prefix, job.ID),
+	})
+}
+
+// sessionJob returns a job submitted by the session of the request, responding
+// with 404 for jobs of other sessions
+func (m *MCP) sessionJob(c *gin.Context, jobID string) (jobs.Job, bool) {
+	job, exists := m.jobs.Get(jobID)
+	if !exists || job.Session != handlers.SessionFromContext(c.Request.Context()) {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Job '%s' not found", jobID),
+		})
+		return jobs.Job{}, false
+	}
+	return job, true
+}
+
+// listJobsHandler returns the jobs of the requesting session, optionally filtered by status
+func (m *MCP) listJobsHandler(c *gin.Context) {
+	status := jobs.Status(c.Query("status"))
+	session := handlers.SessionFromContext(c.Request.Context())
+
+	jobList := make([]jobs.Job, 0)
+	for _, job := range m.jobs.List() {
+		if job.Session == session && (status == "" || job.Status == status) {
+			jobList = append(jobList, job)
+		}
+	}
+	sort.Slice(jobList, func(i, j int) bool {
+		return jobList[i].CreatedAt.Before(jobList[j].CreatedAt)
+	})
+
+	c.JSON(200, gin.H{
+		"jobs":  jobList,
+		"count": len(jobList),
+	})
+}
+
+// getJobHandler returns the status, progress and result of a job
+func (m *MCP) getJobHandler(c *gin.Context) {
+	job, exists := m.sessionJob(c, c.Param("id"))
+	if !exists {
+		return
+	}
+
+	c.JSON(200, job)
+}
+
+// cancelJobHandler cancels a pending or running job
+func (m *MCP) cancelJobHandler(c *gin.Context) {
+	jobID := c.Param("id")
+
+	if _, exists := m.sessionJob(c, jobID); !exists {
+		return
+	}
+
+	job, err := m.jobs.Cancel(jobID)
+	if err != nil {
+		c.JSON(409, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, job)
+}
+
+// deleteJobHandler removes a job and its result, cancelling it if still running
+func (m *MCP) deleteJobHandler(c *gin.Context) {
+	jobID := c.Param("id")
+
+	if _, exists := m.sessionJob(c, jobID); !exists {
+		return
+	}
+	if !m.jobs.Delete(jobID) {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Job '%s' not found", jobID),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"deleted": jobID,
+	})
+}
@@ -0,0 +1,194 @@
+package ginmcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-mcp/handlers"
+	"gin-mcp/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMCP_AsyncToolJob(t *testing.T) {
+	mcp, router := newTestMCP(t, nil)
+
+	err := mcp.RegisterFunc("square", "Square a number", func(ctx context.Context, args squareArgs) (squareResult, error) {
+		jobs.ReportProgress(ctx, 0.5, "squaring")
+		return squareResult{Square: args.N * args.N}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/tools/square?async=true", strings.NewReader(`{"arguments": {"n": 4}}`)))
+	if recorder.Code != 202 {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	var submitted struct {
+		JobID     string `json:"job_id"`
+		StatusURL string `json:"status_url"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &submitted); err != nil {
+		t.Fatal(err)
+	}
+
+	var job struct {
+		Status jobs.Status `json:"status"`
+		Result struct {
+			StructuredContent squareResult `json:"structuredContent"`
+		} `json:"result"`
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.Status.Done() && time.Now().Before(deadline) {
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", submitted.StatusURL, nil))
+		if recorder.Code != 200 {
+			t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if job.Status != jobs.Succeeded || job.Result.StructuredContent.Square != 16 {
+		t.Errorf("Unexpected job: %s", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", submitted.StatusURL+"/cancel", nil))
+	if recorder.Code != 409 {
+		t.Errorf("Cancelling a finished job: status = %d, want 409", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("DELETE", submitted.StatusURL, nil))
+	if recorder.Code != 200 {
+		t.Errorf("DELETE status = %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", submitted.StatusURL, nil))
+	if recorder.Code != 404 {
+		t.Errorf("Expected deleted job to be gone, status = %d", recorder.Code)
+	}
+}
+
+func TestMCP_AsyncToolJobSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.ResourcesDir = filepath.Join(dir, "resources")
+	config.ToolsDir = filepath.Join(dir, "tools")
+	config.ModelsDir = ""
+
+	mcp, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mcp.Stop() })
+
+	// Host authentication sets a verified identity that overrides the header
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Request = c.Request.WithContext(WithSession(c.Request.Context(), user))
+		}
+	})
+	if err := mcp.SetupRoutes(router); err != nil {
+		t.Fatal(err)
+	}
+
+	err = mcp.RegisterFunc("square", "Square a number", func(ctx context.Context, args squareArgs) (squareResult, error) {
+		return squareResult{Square: args.N * args.N}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcp.Use(func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+		if call.Session == "mallory" {
+			return nil, fmt.Errorf("%w: %s", handlers.ErrAccessDenied, call.Session)
+		}
+		output, err := next(ctx, call)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Replace(output, []byte(`"square":16`), []byte(`"square":16,"audited":true`), 1), nil
+	})
+
+	serve := func(method, path, user, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(SessionHeader, "alice")
+		if user != "" {
+			request.Header.Set("X-User", user)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// A rejected call fails the request and creates no job
+	if recorder := serve("POST", "/mcp/tools/square?async=true", "mallory", `{"arguments": {"n": 4}}`); recorder.Code != 403 {
+		t.Fatalf("status = %d, want 403, body = %s", recorder.Code, recorder.Body.String())
+	}
+	if count := len(mcp.jobs.List()); count != 0 {
+		t.Fatalf("Expected no job for a rejected call, got %d", count)
+	}
+
+	recorder := serve("POST", "/mcp/tools/square?async=true", "", `{"arguments": {"n": 4}}`)
+	if recorder.Code != 202 {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	var submitted struct {
+		StatusURL string `json:"status_url"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &submitted); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interceptors post-process the result of the job
+	var job jobs.Job
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.Status.Done() && time.Now().Before(deadline) {
+		if err := json.Unmarshal(serve("GET", submitted.StatusURL, "", "").Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != jobs.Succeeded || job.Session != "alice" || !bytes.Contains(job.Result, []byte(`"audited":true`)) {
+		t.Fatalf("Unexpected job: %+v", job)
+	}
+
+	// Other sessions cannot see or change the job, even when sending its session header
+	steps := []struct {
+		method string
+		path   string
+		user   string
+		status int
+		want   string
+	}{
+		{"GET", "/mcp/jobs", "bob", 200, `"count":0`},
+		{"GET", "/mcp/jobs", "", 200, `"count":1`},
+		{"GET", submitted.StatusURL, "bob", 404, "not found"},
+		{"POST", submitted.StatusURL + "/cancel", "bob", 404, "not found"},
+		{"DELETE", submitted.StatusURL, "bob", 404, "not found"},
+		{"DELETE", submitted.StatusURL, "", 200, "deleted"},
+	}
+	for _, step := range steps {
+		recorder := serve(step.method, step.path, step.user, "")
+		if recorder.Code != step.status || !strings.Contains(recorder.Body.String(), step.want) {
+			t.Errorf("%s %s as %q: status = %d, body = %s", step.method, step.path, step.user, recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ginmcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gin-mcp/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listModelsHandler returns a list of all available models
+func (m *MCP) listModelsHandler(c *gin.Context) {
+	models := m.registry.ListModels()
+
+	modelList := make([]gin.H, len(models))
+	for i, model := range models {
+		modelList[i] = gin.H{
+			"name":      model.Name,
+			"type":      model.Type,
+			"file_path": model.FilePath,
+			"tool":      registry.ModelToolPrefix + model.Name,
+		}
+	}
+
+	c.JSON(200, gin.H{
+		"models": modelList,
+		"count":  len(modelList),
+	})
+}
+
+// getModelInfoHandler returns information about a specific model
+func (m *MCP) getModelInfoHandler(c *gin.Context) {
+	modelName := nameParam(c)
+
+	model, exists := m.registry.GetModel(modelName)
+	if !exists {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Model '%s' not found", modelName),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"name":      model.Name,
+		"type":      model.Type,
+		"file_path": model.FilePath,
+		"tool":      registry.ModelToolPrefix + model.Name,
+	})
+}
+
+// predictHandler runs a model with the request body as its input and returns
+// the model output as-is
+func (m *MCP) predictHandler(c *gin.Context) {
+	modelName := nameParam(c)
+
+	model, exists := m.registry.GetModel(modelName)
+	if !exists {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("Model '%s' not found", modelName),
+		})
+		return
+	}
+
+	// Read the request body
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": fmt.Sprintf("Failed to read request body: %v", err),
+		})
+		return
+	}
+
+	result, err := m.handler.Predict(c.Request.Context(), model, body)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": fmt.Sprintf("Prediction failed: %v", err),
+		})
+		return
+	}
+
+	c.JSON(200, json.RawMessage(result))
+}
@@ -0,0 +1,145 @@
+package ginmcp
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"gin-mcp/handlers"
+	"gin-mcp/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notificationsHandler streams server notifications, such as resource updates,
+// as server-sent events until the client disconnects
+func (m *MCP) notificationsHandler(c *gin.Context) {
+	notifications, unsubscribe := m.handler.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(200)
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case notification, ok := <-notifications:
+			if !ok {
+				return false
+			}
+			c.SSEvent("message", notification)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// resourcePoller refreshes API resources that set a refresh interval, so that
+// subscribers learn about changes without anyone reading the resource
+type resourcePoller struct {
+	registry *registry.Registry
+	handler  *handlers.MCPHandler
+
+	due     map[string]time.Time // Next refresh per resource
+	running map[string]bool
+	mutex   sync.Mutex
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// newResourcePoller creates a poller for the API resources of a registry
+func newResourcePoller(reg *registry.Registry, handler *handlers.MCPHandler) *resourcePoller {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &resourcePoller{
+		registry: reg,
+		handler:  handler,
+		due:      make(map[string]time.Time),
+		running:  make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// start begins checking for due refreshes every second
+func (p *resourcePoller) start() {
+	p.tick(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				p.tick(now)
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// stop cancels running refreshes and waits for them to finish
+func (p *resourcePoller) stop() {
+	p.stopOnce.Do(func() {
+		// Cancel under the mutex so that no tick starts a refresh afterwards
+		p.mutex.Lock()
+		p.cancel()
+		p.mutex.Unlock()
+
+		p.wg.Wait()
+	})
+}
+
+// tick starts a refresh of every API resource that is due. Resources are
+// fetched on the first tick that sees them, priming the cache to compare against.
+func (p *resourcePoller) tick(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, resource := range p.registry.ListResources() {
+		if resource.API == nil || resource.API.Refresh.IntervalDuration() <= 0 {
+			continue
+		}
+		seen[resource.Name] = true
+
+		if next, known := p.due[resource.Name]; (known && now.Before(next)) || p.running[resource.Name] {
+			continue
+		}
+		p.due[resource.Name] = now.Add(resource.API.Refresh.IntervalDuration())
+		p.running[resource.Name] = true
+
+		p.wg.Add(1)
+		go func(resource *registry.ResourceInfo) {
+			defer p.wg.Done()
+
+			if err := p.handler.RefreshResource(p.ctx, resource); err != nil && p.ctx.Err() == nil {
+				log.Printf("⚠️  Failed to refresh API resource %s: %v", resource.Name, err)
+			}
+
+			p.mutex.Lock()
+			delete(p.running, resource.Name)
+			p.mutex.Unlock()
+		}(resource)
+	}
+
+	// Forget resources that were removed or stopped polling
+	for name := range p.due {
+		if !seen[name] {
+			delete(p.due, name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ginmcp
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMCP_ResourceUpdatedNotifications(t *testing.T) {
+	var version atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version": %d}`, version.Load())
+	}))
+	defer upstream.Close()
+
+	mcp, router := newTestMCP(t, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	definition := fmt.Sprintf("url: %s\nrefresh:\n  interval: 1s\n", upstream.URL)
+	if err := os.WriteFile(filepath.Join(mcp.config.ResourcesDir, "status.api.yaml"), []byte(definition), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := http.Get(server.URL + "/mcp/notifications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Content-Type = %s, want text/event-stream", contentType)
+	}
+
+	// Change the upstream once the poller fetched the first version
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if resource, exists := mcp.registry.GetResource("status"); exists {
+			if _, err := mcp.handler.AccessResource(resource, []byte(`{}`)); err == nil {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("API resource was not registered")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	version.Store(1)
+
+	lines := make(chan string, 64)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("Notification stream ended")
+			}
+			if strings.HasPrefix(line, "data:") {
+				if !strings.Contains(line, `"method":"notifications/resources/updated"`) || !strings.Contains(line, `"name":"status"`) {
+					t.Errorf("Unexpected notification %s", line)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Expected a resource updated notification")
+		}
+	}
+}
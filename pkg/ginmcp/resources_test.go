@@ -0,0 +1,339 @@
+package ginmcp
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMCP_NestedResources(t *testing.T) {
+	mcp, router := newTestMCP(t, nil)
+
+	// Subdirectories created after startup are watched as they appear
+	for _, file := range []string{"docs/api.md", "specs/api.md", "specs/v2/api.md"} {
+		path := filepath.Join(mcp.config.ResourcesDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# "+file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitFor := func(condition func() bool, message string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !condition() {
+			if time.Now().After(deadline) {
+				t.Fatal(message)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	waitFor(func() bool { return mcp.registry.GetResourceCount() == 3 }, "Nested resources were not registered")
+
+	for _, name := range []string{"docs/api", "specs/api", "specs/v2/api"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/resources/"+name, strings.NewReader(`{}`)))
+		if recorder.Code != 200 {
+			t.Fatalf("POST %s returned %d: %s", name, recorder.Code, recorder.Body.String())
+		}
+
+		var response struct {
+			Contents []struct {
+				Text string `json:"text"`
+			} `json:"contents"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if want := "# " + name + ".md"; response.Contents[0].Text != want {
+			t.Errorf("%s text = %q, want %q", name, response.Contents[0].Text, want)
+		}
+	}
+
+	// Removing a directory unregisters everything below it
+	if err := os.RemoveAll(filepath.Join(mcp.config.ResourcesDir, "specs")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(func() bool { return mcp.registry.GetResourceCount() == 1 }, "Resources of a removed directory stayed registered")
+	if _, exists := mcp.registry.GetResource("docs/api"); !exists {
+		t.Error("Expected docs/api to stay registered")
+	}
+}
+
+func TestMCP_NestedTools(t *testing.T) {
+	mcp, _ := newTestMCP(t, nil)
+
+	// Tool names cannot contain slashes, so directories are joined with _
+	for _, file := range []string{"github/issues.http.yaml", "github/bad name.http.yaml"} {
+		path := filepath.Join(mcp.config.ToolsDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("url: https://api.github.com/issues\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, exists := mcp.registry.GetTool("github_issues"); exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Nested tool was not registered as github_issues, tools: %v", mcp.registry.GetToolNames())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	for _, name := range mcp.registry.GetToolNames() {
+		if strings.ContainsAny(name, "/ ") {
+			t.Errorf("Expected invalid tool names to be rejected, got %q", name)
+		}
+	}
+}
+
+func TestMCP_ReadResourceByURI(t *testing.T) {
+	config := DefaultConfig()
+	config.ResourceURIBase = "mcp://project/"
+	mcp, router := newTestMCP(t, config)
+
+	path := filepath.Join(mcp.config.ResourcesDir, "docs", "api.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# API"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if resource, exists := mcp.registry.GetResourceByURI("mcp://project/docs/api.md"); exists {
+			if resource.Name != "docs/api" {
+				t.Fatalf("Resource name = %s, want docs/api", resource.Name)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Resource was not indexed by its URI")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"by uri", `{"uri": "mcp://project/docs/api.md"}`, 200},
+		{"by name", `{"name": "docs/api"}`, 200},
+		{"unknown uri", `{"uri": "mcp://project/docs/missing.md"}`, 404},
+		{"server path", `{"uri": "file://` + path + `"}`, 404},
+		{"no reference", `{}`, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/resources", strings.NewReader(tt.body)))
+			if recorder.Code != tt.wantCode {
+				t.Fatalf("Status = %d, want %d: %s", recorder.Code, tt.wantCode, recorder.Body.String())
+			}
+			if tt.wantCode != 200 {
+				return
+			}
+
+			var response struct {
+				Contents []struct {
+					URI  string `json:"uri"`
+					Text string `json:"text"`
+				} `json:"contents"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if content := response.Contents[0]; content.URI != "mcp://project/docs/api.md" || content.Text != "# API" {
+				t.Errorf("Unexpected content %+v", content)
+			}
+		})
+	}
+	// Listings identify resources by name and URI, not by server path
+	for _, target := range []string{"/mcp/resources", "/mcp/resources/docs/api", "/mcp/registry"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		if recorder.Code != 200 {
+			t.Fatalf("GET %s returned %d: %s", target, recorder.Code, recorder.Body.String())
+		}
+		if body := recorder.Body.String(); strings.Contains(body, mcp.config.ResourcesDir) || !strings.Contains(body, "mcp://project/docs/api.md") {
+			t.Errorf("GET %s = %s, want the URI without the server path", target, body)
+		}
+	}
+}
+
+func TestMCP_ResourceMetadataSidecar(t *testing.T) {
+	mcp, router := newTestMCP(t, nil)
+
+	path := filepath.Join(mcp.config.ResourcesDir, "report.csv")
+	if err := os.WriteFile(path, []byte("region,total\neu,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".meta.json", []byte(`{"title": "Quarterly report"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func() []map[string]interface{} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/mcp/resources", nil))
+		var response struct {
+			Resources []map[string]interface{} `json:"resources"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Resources
+	}
+
+	// The sidecar is not a resource of its own, and edits to it show up in listings
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resources := list()
+		if len(resources) == 1 && resources[0]["title"] == "Quarterly report" {
+			if resources[0]["size"] != float64(18) {
+				t.Errorf("size = %v, want 18", resources[0]["size"])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the resource with its title, got %v", resources)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := os.WriteFile(path+".meta.json", []byte(`{"title": "Annual report"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		resources := list()
+		if len(resources) == 1 && resources[0]["title"] == "Annual report" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the updated title, got %v", resources)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestMCP_SearchResources(t *testing.T) {
+	mcp, router := newTestMCP(t, nil)
+
+	files := map[string]string{
+		"runbooks/deploy.md": "# Deploy\n\nRoll out a release with the deploy script.",
+		"runbooks/backup.md": "# Backup\n\nSnapshots run nightly.",
+	}
+	for file, content := range files {
+		path := filepath.Join(mcp.config.ResourcesDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(query string) []string {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/mcp/search?q="+query, nil))
+		if recorder.Code != 200 {
+			t.Fatalf("Search returned %d: %s", recorder.Code, recorder.Body.String())
+		}
+		var response struct {
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(response.Results))
+		for i, result := range response.Results {
+			names[i] = result.Name
+		}
+		return names
+	}
+
+	waitFor := func(query, want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			names := search(query)
+			if strings.Join(names, ",") == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Search for %s = %v, want %s", query, names, want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// Created, changed and removed files are reflected in the index
+	waitFor("deploy", "runbooks/deploy")
+
+	backup := filepath.Join(mcp.config.ResourcesDir, "runbooks", "backup.md")
+	if err := os.WriteFile(backup, []byte("# Backup\n\nSnapshots run before each deploy."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("snapshots", "runbooks/backup")
+	waitFor("deploy", "runbooks/deploy,runbooks/backup")
+
+	if err := os.Remove(backup); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("snapshots", "")
+
+	// Resources registered by the host application are indexed too
+	manual := filepath.Join(t.TempDir(), "oncall.md")
+	if err := os.WriteFile(manual, []byte("# On call\n\nPage the escalation rota."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := mcp.GetRegistry().RegisterResource("oncall", manual); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("escalation", "oncall")
+	mcp.GetRegistry().UnregisterResource("oncall")
+	waitFor("escalation", "")
+
+	// The tool returns the matches as resource links
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/mcp/tools/search_resources", strings.NewReader(`{"arguments": {"query": "release"}}`)))
+	if recorder.Code != 200 {
+		t.Fatalf("Tool returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response struct {
+		Content []map[string]interface{} `json:"content"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Content) != 1 || response.Content[0]["type"] != "resource_link" || response.Content[0]["uri"] != "file:///runbooks/deploy.md" {
+		t.Fatalf("Unexpected tool content %v", response.Content)
+	}
+	if mimeType := response.Content[0]["mime_type"]; mimeType != "text/markdown" {
+		t.Errorf("mime_type = %v, want text/markdown", mimeType)
+	}
+	if snippet, _ := response.Content[0]["description"].(string); !strings.Contains(snippet, "Roll out a release") {
+		t.Errorf("Snippet = %q, want the matching passage", snippet)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/mcp/search", nil))
+	if recorder.Code != 400 {
+		t.Errorf("Search without a query returned %d, want 400", recorder.Code)
+	}
+}
@@ -0,0 +1,221 @@
+package ginmcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"gin-mcp/handlers"
+	"gin-mcp/registry"
+)
+
+// RouteTool describes a route of the host Gin engine that is exposed as an MCP tool.
+// Path parameters (":id", "*path") are always filled from arguments of the same name.
+type RouteTool struct {
+	Method      string                 // HTTP method of the route (default: "GET")
+	Path        string                 // Route path as registered on the engine, e.g. "/api/users/:id"
+	Name        string                 // Tool name
+	Description string                 // Tool description
+	QueryParams []string               // Arguments sent as query parameters
+	BodyParams  []string               // Arguments sent in the JSON body (default: all remaining arguments)
+	InputSchema map[string]interface{} // Argument schema (generated from the parameters if nil)
+}
+
+// ExposeRoute registers a route of the engine passed to SetupRoutes as an MCP tool.
+// Calls are served in-process through the engine, without a network hop.
+func (m *MCP) ExposeRoute(route RouteTool) error {
+	if m.engine == nil {
+		return fmt.Errorf("SetupRoutes must be called before exposing routes")
+	}
+	if route.Name == "" {
+		return fmt.Errorf("route tool name cannot be empty")
+	}
+
+	route.Method = strings.ToUpper(route.Method)
+	if route.Method == "" {
+		route.Method = http.MethodGet
+	}
+
+	found := false
+	for _, info := range m.engine.Routes() {
+		if info.Method == route.Method && info.Path == route.Path {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("route %s %s is not registered on the engine", route.Method, route.Path)
+	}
+
+	description := route.Description
+	if description == "" {
+		description = fmt.Sprintf("%s %s", route.Method, route.Path)
+	}
+
+	inputSchema := route.InputSchema
+	if inputSchema == nil {
+		inputSchema = route.generateInputSchema()
+	}
+
+	return m.registry.RegisterToolInfo(&registry.ToolInfo{
+		Name:        route.Name,
+		Description: description,
+		FilePath:    route.Path,
+		Type:        registry.RouteTool,
+		InputSchema: inputSchema,
+		Handler:     registry.ToolFunc(m.routeToolFunc(route)),
+	})
+}
+
+// routeToolFunc returns a handler that invokes the route through the engine
+func (m *MCP) routeToolFunc(route RouteTool) registry.ToolFunc {
+	return func(ctx context.Context, input []byte) ([]byte, error) {
+		var data struct {
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(input, &data); err != nil {
+			return nil, fmt.Errorf("failed to parse input: %w", err)
+		}
+
+		request, err := route.buildRequest(ctx, data.Arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		recorder := httptest.NewRecorder()
+		m.engine.ServeHTTP(recorder, request)
+
+		return handlers.FormatHTTPResult(recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.Bytes())
+	}
+}
+
+// buildRequest maps tool arguments onto the route path, query and body
+func (route RouteTool) buildRequest(ctx context.Context, arguments map[string]interface{}) (*http.Request, error) {
+	remaining := make(map[string]interface{}, len(arguments))
+	for name, value := range arguments {
+		remaining[name] = value
+	}
+
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+
+		name := segment[1:]
+		value, exists := remaining[name]
+		if !exists {
+			return nil, fmt.Errorf("path parameter '%s' is required", name)
+		}
+		delete(remaining, name)
+
+		// A catch-all parameter spans segments, each of them is escaped
+		parts := []string{fmt.Sprint(value)}
+		if strings.HasPrefix(segment, "*") {
+			parts = strings.Split(strings.TrimPrefix(parts[0], "/"), "/")
+		}
+		for j, part := range parts {
+			if part == "." || part == ".." {
+				return nil, fmt.Errorf("path parameter '%s' must not contain . or .. segments", name)
+			}
+			parts[j] = url.PathEscape(part)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+
+	query := url.Values{}
+	for _, name := range route.QueryParams {
+		if value, exists := remaining[name]; exists {
+			query.Set(name, fmt.Sprint(value))
+			delete(remaining, name)
+		}
+	}
+
+	var body io.Reader
+	if route.sendsBody() {
+		payload := remaining
+		if route.BodyParams != nil {
+			payload = make(map[string]interface{}, len(route.BodyParams))
+			for _, name := range route.BodyParams {
+				if value, exists := remaining[name]; exists {
+					payload[name] = value
+				}
+			}
+		}
+
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	target := strings.Join(segments, "/")
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, route.Method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	return request, nil
+}
+
+// sendsBody reports whether the route method carries a request body
+func (route RouteTool) sendsBody() bool {
+	switch route.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// generateInputSchema builds an argument schema from the route parameters
+func (route RouteTool) generateInputSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for _, segment := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			properties[segment[1:]] = map[string]interface{}{
+				"type":        "string",
+				"description": "Path parameter",
+			}
+			required = append(required, segment[1:])
+		}
+	}
+
+	for _, name := range route.QueryParams {
+		properties[name] = map[string]interface{}{
+			"type":        "string",
+			"description": "Query parameter",
+		}
+	}
+
+	for _, name := range route.BodyParams {
+		properties[name] = map[string]interface{}{
+			"description": "Body field",
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
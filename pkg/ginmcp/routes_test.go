@@ -0,0 +1,134 @@
+package ginmcp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMCP_ExposeRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/api/users/:id", func(c *gin.Context) {
+		c.JSON(200, gin.H{"id": c.Param("id"), "fields": c.Query("fields")})
+	})
+	router.GET("/api/files/*path", func(c *gin.Context) {
+		c.JSON(200, gin.H{"path": c.Param("path"), "admin": c.Query("admin")})
+	})
+	router.POST("/api/users", func(c *gin.Context) {
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(201, body)
+	})
+
+	dir := t.TempDir()
+	mcp, err := New(&MCPConfig{
+		ResourcesDir: filepath.Join(dir, "resources"),
+		ToolsDir:     filepath.Join(dir, "tools"),
+		Prefix:       "/mcp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mcp.Stop()
+
+	if err := mcp.ExposeRoute(RouteTool{Path: "/api/users/:id", Name: "get_user"}); err == nil {
+		t.Errorf("Expected ExposeRoute() to fail before SetupRoutes")
+	}
+
+	if err := mcp.SetupRoutes(router); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mcp.ExposeRoute(RouteTool{Path: "/api/unknown", Name: "unknown"}); err == nil {
+		t.Errorf("Expected ExposeRoute() to reject unregistered routes")
+	}
+
+	routes := []RouteTool{
+		{Method: "GET", Path: "/api/users/:id", Name: "get_user", QueryParams: []string{"fields"}},
+		{Method: "POST", Path: "/api/users", Name: "create_user"},
+		{Method: "GET", Path: "/api/files/*path", Name: "get_file"},
+	}
+	for _, route := range routes {
+		if err := mcp.ExposeRoute(route); err != nil {
+			t.Fatalf("ExposeRoute(%s) error = %v", route.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		input   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "path and query parameters",
+			tool:  "get_user",
+			input: `{"arguments": {"id": "42", "fields": "name"}}`,
+			want:  map[string]interface{}{"id": "42", "fields": "name"},
+		},
+		{
+			name:  "body",
+			tool:  "create_user",
+			input: `{"arguments": {"name": "alice"}}`,
+			want:  map[string]interface{}{"name": "alice"},
+		},
+		{
+			name:  "catch-all parameter",
+			tool:  "get_file",
+			input: `{"arguments": {"path": "docs/a?admin=1#top"}}`,
+			want:  map[string]interface{}{"path": "/docs/a?admin=1#top", "admin": ""},
+		},
+		{
+			name:    "catch-all parameter with ..",
+			tool:    "get_file",
+			input:   `{"arguments": {"path": "docs/../../admin"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "path parameter with ..",
+			tool:    "get_user",
+			input:   `{"arguments": {"id": ".."}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, exists := mcp.GetRegistry().GetTool(tt.tool)
+			if !exists {
+				t.Fatalf("Tool %s not registered", tt.tool)
+			}
+
+			result, err := mcp.GetHandler().ExecuteTool(tool, []byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected ExecuteTool() to fail, got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteTool() error = %v", err)
+			}
+
+			var content map[string]interface{}
+			if err := json.Unmarshal(result, &content); err != nil {
+				t.Fatal(err)
+			}
+
+			structured, _ := content["structuredContent"].(map[string]interface{})
+			for key, value := range tt.want {
+				if structured[key] != value {
+					t.Errorf("structuredContent[%s] = %v, want %v", key, structured[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package ginmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-mcp/handlers"
+	"gin-mcp/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scheduleHistoryLimit is the number of runs kept per schedule
+const scheduleHistoryLimit = 20
+
+// Schedule runs a tool periodically with fixed arguments
+type Schedule struct {
+	Name      string                 `json:"name"`                // Unique name (default: <tool>-<n>)
+	Tool      string                 `json:"tool"`                // Tool to execute
+	Cron      string                 `json:"cron"`                // Cron expression, descriptor such as @daily or @every <duration>
+	Arguments map[string]interface{} `json:"arguments,omitempty"` // Arguments passed to the tool on every run
+}
+
+// ScheduleRun is the outcome of a single scheduled execution
+type ScheduleRun struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Status     string    `json:"status"` // succeeded, failed or skipped
+	Error      string    `json:"error,omitempty"`
+}
+
+// scheduleEntry is the state of a schedule known to the scheduler
+type scheduleEntry struct {
+	Schedule
+	Source  string        `json:"source"` // config or manifest
+	Error   string        `json:"error,omitempty"`
+	NextRun *time.Time    `json:"next_run,omitempty"`
+	LastRun *ScheduleRun  `json:"last_run,omitempty"`
+	History []ScheduleRun `json:"history"`
+	Running bool          `json:"running"`
+
+	cron *cronSchedule
+}
+
+// scheduler executes tools on cron schedules from the config and from tool manifests
+type scheduler struct {
+	schedules []Schedule
+	registry  *registry.Registry
+	handler   *handlers.MCPHandler
+
+	entries map[string]*scheduleEntry
+	mutex   sync.Mutex
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// newScheduler creates a scheduler for the configured schedules
+func newScheduler(schedules []Schedule, reg *registry.Registry, handler *handlers.MCPHandler) *scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &scheduler{
+		schedules: schedules,
+		registry:  reg,
+		handler:   handler,
+		entries:   make(map[string]*scheduleEntry),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// start begins checking schedules every second
+func (s *scheduler) start() {
+	s.tick(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				s.tick(now)
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	log.Printf("⏰ Scheduler started")
+}
+
+// stop cancels running executions and waits for them to finish
+func (s *scheduler) stop() {
+	s.stopOnce.Do(func() {
+		// Cancel under the mutex so that no tick starts a run afterwards
+		s.mutex.Lock()
+		s.cancel()
+		s.mutex.Unlock()
+
+		s.wg.Wait()
+	})
+}
+
+// tick refreshes the schedules and starts every run that is due
+func (s *scheduler) tick(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.sync(now)
+
+	for _, entry := range s.entries {
+		if entry.NextRun == nil || entry.NextRun.After(now) {
+			continue
+		}
+
+		if entry.Running {
+			// Never overlap runs of the same schedule
+			s.record(entry, ScheduleRun{StartedAt: now, FinishedAt: now, Status: "skipped", Error: "previous run still in progress"})
+		} else {
+			entry.Running = true
+			s.wg.Add(1)
+			go s.run(entry)
+		}
+
+		s.scheduleNext(entry, now)
+	}
+}
+
+// sync reconciles the entries with the configured and manifest schedules.
+// Schedules that did not change keep their state and history.
+func (s *scheduler) sync(now time.Time) {
+	desired := make(map[string]*scheduleEntry)
+
+	add := func(schedule Schedule, source string) {
+		if _, exists := desired[schedule.Name]; exists {
+			log.Printf("⚠️  Ignoring duplicate schedule %s", schedule.Name)
+			return
+		}
+		desired[schedule.Name] = &scheduleEntry{Schedule: schedule, Source: source}
+	}
+
+	for i, schedule := range s.schedules {
+		if schedule.Name == "" {
+			schedule.Name = fmt.Sprintf("%s-%d", schedule.Tool, i+1)
+		}
+		add(schedule, "config")
+	}
+
+	for _, tool := range s.registry.ListTools() {
+		if tool.Manifest == nil {
+			continue
+		}
+		for i, toolSchedule := range tool.Manifest.Schedules {
+			schedule := Schedule{
+				Name:      toolSchedule.Name,
+				Tool:      tool.Name,
+				Cron:      toolSchedule.Cron,
+				Arguments: toolSchedule.Arguments,
+			}
+			if schedule.Name == "" {
+				schedule.Name = fmt.Sprintf("%s-%d", tool.Name, i+1)
+			}
+			add(schedule, "manifest")
+		}
+	}
+
+	for name, entry := range desired {
+		existing, exists := s.entries[name]
+		if exists && reflect.DeepEqual(existing.Schedule, entry.Schedule) {
+			continue
+		}
+
+		if exists {
+			entry.History = existing.History
+			entry.LastRun = existing.LastRun
+			entry.Running = existing.Running
+		}
+
+		cron, err := parseCron(entry.Cron)
+		if err != nil {
+			entry.Error = err.Error()
+			log.Printf("⚠️  Invalid schedule %s: %v", name, err)
+		} else {
+			entry.cron = cron
+			s.scheduleNext(entry, now)
+		}
+
+		s.entries[name] = entry
+		log.Printf("⏰ Schedule %s: %s runs at %q", name, entry.Tool, entry.Cron)
+	}
+
+	for name := range s.entries {
+		if _, exists := desired[name]; !exists {
+			delete(s.entries, name)
+			log.Printf("🗑️  Schedule %s removed", name)
+		}
+	}
+}
+
+// scheduleNext computes the next run of an entry after now
+func (s *scheduler) scheduleNext(entry *scheduleEntry, now time.Time) {
+	entry.NextRun = nil
+	if next := entry.cron.next(now); !next.IsZero() {
+		entry.NextRun = &next
+	}
+}
+
+// run executes the tool of a schedule and records the outcome
+func (s *scheduler) run(entry *scheduleEntry) {
+	defer s.wg.Done()
+
+	run := ScheduleRun{StartedAt: time.Now()}
+	if err := s.execute(entry.Schedule); err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+		log.Printf("❌ Scheduled run %s failed: %v", entry.Name, err)
+	} else {
+		run.Status = "succeeded"
+		log.Printf("⏰ Scheduled run %s succeeded", entry.Name)
+	}
+	run.FinishedAt = time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The entry may have been replaced while running if its schedule changed
+	if current, exists := s.entries[entry.Name]; exists {
+		current.Running = false
+		s.record(current, run)
+	}
+}
+
+// execute runs a scheduled tool. Tool error results are reported as failures.
+func (s *scheduler) execute(schedule Schedule) error {
+	tool, exists := s.registry.GetTool(schedule.Tool)
+	if !exists {
+		return fmt.Errorf("tool %s not found", schedule.Tool)
+	}
+
+	arguments := schedule.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	input, err := json.Marshal(map[string]interface{}{"arguments": arguments})
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	output, err := s.handler.ExecuteToolContext(s.ctx, tool, input)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		IsError bool `json:"isError"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if json.Unmarshal(output, &result) == nil && result.IsError {
+		texts := make([]string, len(result.Content))
+		for i, content := range result.Content {
+			texts[i] = content.Text
+		}
+		return fmt.Errorf("%s", strings.Join(texts, "\n"))
+	}
+
+	return nil
+}
+
+// record appends a run to the history of an entry. The caller must hold the mutex.
+func (s *scheduler) record(entry *scheduleEntry, run ScheduleRun) {
+	entry.LastRun = &run
+	entry.History = append(entry.History, run)
+	if len(entry.History) > scheduleHistoryLimit {
+		entry.History = entry.History[len(entry.History)-scheduleHistoryLimit:]
+	}
+}
+
+// list returns copies of all schedules sorted by name
+func (s *scheduler) list() []scheduleEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries := make([]scheduleEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		copied := *entry
+		copied.History = append([]ScheduleRun{}, entry.History...)
+		entries = append(entries, copied)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// listSchedulesHandler returns every schedule with its last and next run and history
+func (m *MCP) listSchedulesHandler(c *gin.Context) {
+	schedules := m.scheduler.list()
+
+	c.JSON(200, gin.H{
+		"schedules": schedules,
+		"count":     len(schedules),
+	})
+}
+
+// scheduleSummary returns the last and next run of every schedule for the health check
+func (m *MCP) scheduleSummary() map[string]gin.H {
+	summary := make(map[string]gin.H)
+	for _, entry := range m.scheduler.list() {
+		item := gin.H{"tool": entry.Tool, "next_run": entry.NextRun}
+		if entry.LastRun != nil {
+			item["last_run"] = entry.LastRun.StartedAt
+			item["last_status"] = entry.LastRun.Status
+		}
+		summary[entry.Name] = item
+	}
+	return summary
+}
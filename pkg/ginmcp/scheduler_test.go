@@ -0,0 +1,90 @@
+package ginmcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gin-mcp/handlers"
+	"gin-mcp/registry"
+)
+
+func TestScheduler_Runs(t *testing.T) {
+	reg := registry.NewRegistry()
+	handler := handlers.NewMCPHandler()
+
+	calls := make(chan squareArgs, 2)
+	err := reg.RegisterFunc("square", "Square a number", func(ctx context.Context, args squareArgs) (squareResult, error) {
+		calls <- args
+		if args.N < 0 {
+			return squareResult{}, errors.New("negative input")
+		}
+		return squareResult{Square: args.N * args.N}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file-backed tool with a manifest contributes its own schedule
+	dir := t.TempDir()
+	toolPath := filepath.Join(dir, "status.http.yaml")
+	if err := os.WriteFile(toolPath, []byte("url: http://127.0.0.1:1/status\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "schedules:\n  - name: nightly-status\n    cron: \"@daily\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "status.manifest.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterTool("status", toolPath, "status"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newScheduler([]Schedule{
+		{Tool: "square", Cron: "* * * * *", Arguments: map[string]interface{}{"n": 3}},
+		{Name: "broken", Tool: "square", Cron: "* * * * *", Arguments: map[string]interface{}{"n": -1}},
+	}, reg, handler)
+	defer s.stop()
+
+	base := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+	s.tick(base)
+
+	entries := s.list()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 schedules, got %+v", entries)
+	}
+	if entries[1].Name != "nightly-status" || entries[1].Source != "manifest" || entries[1].NextRun == nil || !entries[1].NextRun.Equal(base.AddDate(0, 0, 1).Truncate(24*time.Hour)) {
+		t.Errorf("Unexpected manifest schedule: %+v", entries[1])
+	}
+
+	// Both config schedules are due one minute later
+	s.tick(base.Add(time.Minute))
+	for i := 0; i < 2; i++ {
+		select {
+		case <-calls:
+		case <-time.After(5 * time.Second):
+			t.Fatal("scheduled tool was not executed")
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		entries = s.list()
+		if entries[0].LastRun != nil && entries[2].LastRun != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if run := entries[0].LastRun; run == nil || run.Status != "failed" || run.Error != "negative input" {
+		t.Errorf("Expected failed run of broken schedule, got %+v", run)
+	}
+	if run := entries[2].LastRun; run == nil || run.Status != "succeeded" {
+		t.Errorf("Expected successful run of square-1, got %+v", run)
+	}
+	if next := entries[2].NextRun; next == nil || !next.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("NextRun = %v", next)
+	}
+}
@@ -0,0 +1,146 @@
+package ginmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gin-mcp/registry"
+	"gin-mcp/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchToolName is the name of the built-in tool searching resources
+const searchToolName = "search_resources"
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// searchArgs are the arguments of the search tool
+type searchArgs struct {
+	Query string `json:"query" description:"Words to look for in the title, description and content of resources"`
+	Limit int    `json:"limit,omitempty" description:"Maximum number of results (default: 10, at most 100)"`
+}
+
+// searchLimit applies the default and the maximum to a requested number of results
+func searchLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSearchLimit
+	}
+	return min(limit, maxSearchLimit)
+}
+
+// indexResource keeps the search index in step with the registry, whether
+// resources come from the watcher or are registered by the host application
+func (m *MCP) indexResource(name string, resource *registry.ResourceInfo) {
+	if resource == nil {
+		m.search.Remove(name)
+		return
+	}
+	if err := m.search.Add(resource); err != nil {
+		m.search.Remove(name)
+		log.Printf("⚠️  %v", err)
+	}
+}
+
+// searchHandler ranks resources by relevance to the words of the q parameter
+func (m *MCP) searchHandler(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(400, gin.H{
+			"error": "Query parameter q is required",
+		})
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			c.JSON(400, gin.H{
+				"error": fmt.Sprintf("Invalid limit: %s", value),
+			})
+			return
+		}
+	}
+
+	results := m.search.Search(query, searchLimit(limit))
+
+	c.JSON(200, gin.H{
+		"query":   query,
+		"results": results,
+		"count":   len(results),
+	})
+}
+
+// registerSearchTool registers the built-in tool that searches resources and
+// returns the matches as resource links, so that clients can read the
+// relevant ones
+func (m *MCP) registerSearchTool() error {
+	handler := func(ctx context.Context, input []byte) ([]byte, error) {
+		var data struct {
+			Arguments searchArgs `json:"arguments"`
+		}
+		if err := json.Unmarshal(input, &data); err != nil {
+			return nil, fmt.Errorf("invalid arguments for tool %s: %w", searchToolName, err)
+		}
+
+		query := strings.TrimSpace(data.Arguments.Query)
+		if query == "" {
+			return json.Marshal(map[string]interface{}{
+				"content": []map[string]interface{}{
+					{"type": "text", "text": "query is required"},
+				},
+				"isError": true,
+			})
+		}
+
+		results := m.search.Search(query, searchLimit(data.Arguments.Limit))
+
+		content := make([]map[string]interface{}, 0, len(results))
+		for _, result := range results {
+			link := map[string]interface{}{
+				"type":      "resource_link",
+				"uri":       result.URI,
+				"name":      result.Name,
+				"mime_type": result.MimeType,
+			}
+			if result.Title != "" {
+				link["title"] = result.Title
+			}
+			if result.Snippet != "" {
+				link["description"] = result.Snippet
+			}
+			content = append(content, link)
+		}
+		if len(content) == 0 {
+			content = append(content, map[string]interface{}{
+				"type": "text",
+				"text": fmt.Sprintf("No resources match %q", query),
+			})
+		}
+
+		return json.Marshal(map[string]interface{}{
+			"content":           content,
+			"structuredContent": map[string]interface{}{"results": results},
+		})
+	}
+
+	return m.registry.RegisterToolInfo(&registry.ToolInfo{
+		Name:        searchToolName,
+		Description: "Full-text search across the registered resources, returning links to the best matches with a snippet of each",
+		Type:        registry.BuiltinTool,
+		InputSchema: registry.SchemaForType(reflect.TypeOf(searchArgs{})),
+		OutputSchema: registry.SchemaForType(reflect.TypeOf(struct {
+			Results []search.Result `json:"results"`
+		}{})),
+		Handler: registry.ToolFunc(handler),
+	})
+}
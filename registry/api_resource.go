@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// apiResourceExtensions are the extensions of API resource definition files
+var apiResourceExtensions = []string{".api.yaml", ".api.yml"}
+
+// APIResourceSpec describes a resource fetched from an upstream HTTP endpoint,
+// loaded from a *.api.yaml file. Header values may reference secrets as ${NAME}.
+type APIResourceSpec struct {
+	Description string            `yaml:"description" json:"description,omitempty"`
+	URL         string            `yaml:"url" json:"url"`
+	Headers     map[string]string `yaml:"headers" json:"-"` // Not exported, they may carry credentials
+	MimeType    string            `yaml:"mime_type" json:"mime_type,omitempty"`
+	Timeout     string            `yaml:"timeout" json:"timeout,omitempty"`
+	Refresh     RefreshPolicy     `yaml:"refresh" json:"refresh"`
+}
+
+// RefreshPolicy controls how long fetched content is served before it is fetched again
+type RefreshPolicy struct {
+	TTL        string `yaml:"ttl" json:"ttl,omitempty"`               // Content is served from the cache this long (default: always revalidate)
+	Revalidate *bool  `yaml:"revalidate" json:"revalidate,omitempty"` // Send ETag and Last-Modified validators once stale (default: true)
+	Interval   string `yaml:"interval" json:"interval,omitempty"`     // Poll in the background, notifying subscribers of changes
+}
+
+// TTLDuration returns how long fetched content stays fresh
+func (p RefreshPolicy) TTLDuration() time.Duration {
+	ttl, _ := time.ParseDuration(p.TTL)
+	return ttl
+}
+
+// IntervalDuration returns the background polling interval, or 0 if polling is disabled
+func (p RefreshPolicy) IntervalDuration() time.Duration {
+	interval, _ := time.ParseDuration(p.Interval)
+	return interval
+}
+
+// Revalidates reports whether stale content is revalidated with conditional requests
+func (p RefreshPolicy) Revalidates() bool {
+	return p.Revalidate == nil || *p.Revalidate
+}
+
+// TimeoutDuration returns the request timeout of the resource, or fallback if none is set
+func (s *APIResourceSpec) TimeoutDuration(fallback time.Duration) time.Duration {
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil || timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
+
+// isAPIResource reports whether a file is an API resource definition
+func isAPIResource(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	for _, ext := range apiResourceExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAPIResource parses and validates an API resource definition file,
+// and checks that the secrets its headers reference exist
+func loadAPIResource(filePath string, lookup func(name string) (string, bool)) (*APIResourceSpec, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API resource definition %s: %w", filePath, err)
+	}
+
+	var spec APIResourceSpec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse API resource definition %s: %w", filePath, err)
+	}
+
+	parsed, err := url.Parse(spec.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("API resource definition %s has an invalid url: %s", filePath, spec.URL)
+	}
+
+	for field, value := range map[string]string{"timeout": spec.Timeout, "refresh.ttl": spec.Refresh.TTL, "refresh.interval": spec.Refresh.Interval} {
+		if value == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(value); err != nil || duration < 0 {
+			return nil, fmt.Errorf("API resource definition %s has an invalid %s: %s", filePath, field, value)
+		}
+	}
+
+	for header, value := range spec.Headers {
+		if _, err := ExpandSecrets(value, lookup); err != nil {
+			return nil, fmt.Errorf("API resource definition %s has an invalid %s header: %w", filePath, header, err)
+		}
+	}
+
+	return &spec, nil
+}
+
+// ExpandSecrets replaces the ${NAME} references of a header value with the
+// secrets returned by lookup. Other dollar signs are kept as they are.
+func ExpandSecrets(value string, lookup func(name string) (string, bool)) (string, error) {
+	var expanded strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			expanded.WriteString(value)
+			return expanded.String(), nil
+		}
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated secret reference")
+		}
+		name := value[start+2 : start+end]
+		if name == "" {
+			return "", fmt.Errorf("empty secret reference")
+		}
+		secret, exists := lookup(name)
+		if !exists {
+			return "", fmt.Errorf("secret %s is not configured", name)
+		}
+		expanded.WriteString(value[:start])
+		expanded.WriteString(secret)
+		value = value[start+end+1:]
+	}
+}
@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// RegisterFunc registers a typed Go function as an in-process tool. The function
+// must have the signature func(context.Context, Args) (Result, error), where Args
+// is a struct. The input and output schemas are derived from Args and Result
+// (see SchemaForType).
+func (r *Registry) RegisterFunc(name, description string, fn interface{}) error {
+	fnValue := reflect.ValueOf(fn)
+	if !fnValue.IsValid() || (fnValue.Kind() == reflect.Func && fnValue.IsNil()) {
+		return fmt.Errorf("tool %s has no function", name)
+	}
+	fnType := fnValue.Type()
+
+	if fnType.Kind() != reflect.Func ||
+		fnType.NumIn() != 2 || fnType.In(0) != contextType ||
+		fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+		return fmt.Errorf("tool %s must have the signature func(context.Context, Args) (Result, error)", name)
+	}
+
+	argsType := fnType.In(1)
+	if argsType.Kind() != reflect.Struct && !(argsType.Kind() == reflect.Ptr && argsType.Elem().Kind() == reflect.Struct) {
+		return fmt.Errorf("arguments of tool %s must be a struct, got %s", name, argsType)
+	}
+
+	inputSchema := SchemaForType(argsType)
+	required, _ := inputSchema["required"].([]string)
+
+	resultType := fnType.Out(0)
+	outputSchema := SchemaForType(resultType)
+	wrapResult := outputSchema["type"] != "object"
+	if wrapResult {
+		// Structured content must be an object, so other results are wrapped
+		outputSchema = map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"result": outputSchema},
+			"required":   []string{"result"},
+		}
+	}
+
+	handler := func(ctx context.Context, input []byte) ([]byte, error) {
+		var data struct {
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(input, &data); err != nil {
+			return nil, fmt.Errorf("failed to parse input: %w", err)
+		}
+
+		var fields map[string]json.RawMessage
+		if len(data.Arguments) > 0 {
+			if err := json.Unmarshal(data.Arguments, &fields); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+		}
+		for _, field := range required {
+			if value, exists := fields[field]; !exists || string(value) == "null" {
+				return nil, fmt.Errorf("invalid arguments for tool %s: missing required argument %s", name, field)
+			}
+		}
+
+		if len(data.Arguments) > 0 {
+			var arguments interface{}
+			if err := json.Unmarshal(data.Arguments, &arguments); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+			if err := checkEnums(arguments, inputSchema, ""); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+		}
+
+		args := reflect.New(argsType)
+		if len(data.Arguments) > 0 {
+			if err := json.Unmarshal(data.Arguments, args.Interface()); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+		}
+		// Functions taking a pointer get an empty struct rather than nil when arguments are omitted
+		if argsType.Kind() == reflect.Ptr && args.Elem().IsNil() {
+			args.Elem().Set(reflect.New(argsType.Elem()))
+		}
+
+		outputs := fnValue.Call([]reflect.Value{reflect.ValueOf(ctx), args.Elem()})
+		if err, _ := outputs[1].Interface().(error); err != nil {
+			return json.Marshal(map[string]interface{}{
+				"content": []map[string]interface{}{
+					{"type": "text", "text": err.Error()},
+				},
+				"isError": true,
+			})
+		}
+
+		var structured interface{} = outputs[0].Interface()
+		if wrapResult {
+			structured = map[string]interface{}{"result": structured}
+		}
+
+		text, err := json.Marshal(structured)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result of tool %s: %w", name, err)
+		}
+
+		return json.Marshal(map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": string(text)},
+			},
+			"structuredContent": json.RawMessage(text),
+		})
+	}
+
+	return r.RegisterToolInfo(&ToolInfo{
+		Name:         name,
+		Description:  description,
+		FilePath:     fnType.String(),
+		Type:         FuncTool,
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		Handler:      ToolFunc(handler),
+	})
+}
+
+// checkEnums verifies that the decoded arguments only take the values the
+// enum tags of the schema allow, in nested objects and lists too
+func checkEnums(value interface{}, schema map[string]interface{}, path string) error {
+	if value == nil {
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		allowed := false
+		for _, option := range enum {
+			if int64Option, ok := option.(int64); ok {
+				option = float64(int64Option)
+			}
+			if option == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("argument %s must be one of %v", path, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for key, field := range v {
+			fieldSchema, _ := properties[key].(map[string]interface{})
+			if fieldSchema == nil {
+				fieldSchema = additional
+			}
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if err := checkEnums(field, fieldSchema, fieldPath); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range v {
+			if err := checkEnums(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type lookupArgs struct {
+	Query  string   `json:"query" description:"Search term"`
+	Limit  int      `json:"limit,omitempty" enum:"10,50,100"`
+	Fields []string `json:"fields,omitempty" enum:"title,body"`
+	Strict *bool    `json:"strict"`
+	Secret string   `json:"-"`
+}
+
+type lookupResult struct {
+	Matches []string `json:"matches"`
+}
+
+func TestSchemaForType(t *testing.T) {
+	schema := SchemaForType(reflect.TypeOf(lookupArgs{}))
+
+	properties := schema["properties"].(map[string]interface{})
+	if _, exists := properties["Secret"]; exists {
+		t.Errorf("Expected fields tagged json:\"-\" to be skipped")
+	}
+
+	query := properties["query"].(map[string]interface{})
+	if query["type"] != "string" || query["description"] != "Search term" {
+		t.Errorf("query schema = %v", query)
+	}
+
+	limit := properties["limit"].(map[string]interface{})
+	if !reflect.DeepEqual(limit["enum"], []interface{}{int64(10), int64(50), int64(100)}) {
+		t.Errorf("limit enum = %v", limit["enum"])
+	}
+
+	fields := properties["fields"].(map[string]interface{})
+	if items, _ := fields["items"].(map[string]interface{}); fields["type"] != "array" || !reflect.DeepEqual(items["enum"], []interface{}{"title", "body"}) {
+		t.Errorf("fields schema = %v", fields)
+	}
+
+	if !reflect.DeepEqual(schema["required"], []string{"query"}) {
+		t.Errorf("required = %v, want [query]", schema["required"])
+	}
+}
+
+func TestRegistry_RegisterFunc(t *testing.T) {
+	reg := NewRegistry()
+
+	lookup := func(ctx context.Context, args lookupArgs) (lookupResult, error) {
+		if args.Query == "" {
+			return lookupResult{}, errors.New("query is required")
+		}
+		return lookupResult{Matches: []string{args.Query}}, nil
+	}
+
+	if err := reg.RegisterFunc("lookup", "Look things up", lookup); err != nil {
+		t.Fatalf("RegisterFunc() error = %v", err)
+	}
+
+	if err := reg.RegisterFunc("invalid", "", func(s string) error { return nil }); err == nil {
+		t.Errorf("Expected RegisterFunc() to reject invalid signatures")
+	}
+	if err := reg.RegisterFunc("nil", "", nil); err == nil {
+		t.Errorf("Expected RegisterFunc() to reject a nil function")
+	}
+
+	tool, exists := reg.GetTool("lookup")
+	if !exists || tool.Type != FuncTool || tool.OutputSchema == nil {
+		t.Fatalf("Expected function tool with output schema, got %+v", tool)
+	}
+
+	handler := tool.Handler.(ToolFunc)
+
+	tests := []struct {
+		name      string
+		input     string
+		wantError bool
+		wantErr   bool
+	}{
+		{name: "valid arguments", input: `{"arguments": {"query": "gin"}}`},
+		{name: "function error", input: `{"arguments": {"query": ""}}`, wantError: true},
+		{name: "missing required argument", input: `{"arguments": {}}`, wantErr: true},
+		{name: "null required argument", input: `{"arguments": {"query": null}}`, wantErr: true},
+		{name: "enum value", input: `{"arguments": {"query": "gin", "limit": 50, "fields": ["title", "body"]}}`},
+		{name: "value outside the enum", input: `{"arguments": {"query": "gin", "limit": 20}}`, wantErr: true},
+		{name: "item outside the enum", input: `{"arguments": {"query": "gin", "fields": ["title", "author"]}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := handler(context.Background(), []byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var result struct {
+				IsError           bool         `json:"isError"`
+				StructuredContent lookupResult `json:"structuredContent"`
+			}
+			if err := json.Unmarshal(output, &result); err != nil {
+				t.Fatal(err)
+			}
+
+			if result.IsError != tt.wantError {
+				t.Errorf("isError = %v, want %v", result.IsError, tt.wantError)
+			}
+			if !tt.wantError && len(result.StructuredContent.Matches) != 1 {
+				t.Errorf("structuredContent = %+v", result.StructuredContent)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterFuncPointerArguments(t *testing.T) {
+	reg := NewRegistry()
+
+	type optionalArgs struct {
+		Verbose bool `json:"verbose,omitempty"`
+	}
+	err := reg.RegisterFunc("status", "Report status", func(ctx context.Context, args *optionalArgs) (string, error) {
+		if args.Verbose {
+			return "all systems go", nil
+		}
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterFunc() error = %v", err)
+	}
+
+	tool, _ := reg.GetTool("status")
+	handler := tool.Handler.(ToolFunc)
+
+	for _, input := range []string{`{}`, `{"arguments": null}`, `{"arguments": {"verbose": true}}`} {
+		if _, err := handler(context.Background(), []byte(input)); err != nil {
+			t.Errorf("handler(%s) error = %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"gopkg.in/yaml.v3"
+)
+
+// grpcResolveTimeout bounds descriptor resolution through server reflection
+const grpcResolveTimeout = 5 * time.Second
+
+// GRPCToolSpec describes a gRPC tool loaded from a *.grpc.yaml file. The method
+// descriptor is taken from DescriptorSet when set, or from server reflection.
+type GRPCToolSpec struct {
+	Description   string            `yaml:"description" json:"description,omitempty"`
+	Target        string            `yaml:"target" json:"target"`                           // host:port of the gRPC server
+	Method        string            `yaml:"method" json:"method"`                           // package.Service/Method
+	DescriptorSet string            `yaml:"descriptor_set" json:"descriptor_set,omitempty"` // FileDescriptorSet, relative to the definition file
+	Metadata      map[string]string `yaml:"metadata" json:"metadata,omitempty"`             // Outgoing request metadata
+	Timeout       string            `yaml:"timeout" json:"timeout,omitempty"`
+
+	conn        *grpc.ClientConn
+	method      protoreflect.MethodDescriptor
+	inputSchema map[string]interface{}
+	onResolve   func(inputSchema map[string]interface{}) // Called when the method is resolved after registration
+	mutex       sync.Mutex
+
+	calls     int  // Calls using the connection
+	closed    bool // Close was called, the connection closes once the calls return
+	connMutex sync.Mutex
+}
+
+func (s *GRPCToolSpec) definedDescription() string                 { return s.Description }
+func (s *GRPCToolSpec) definedInputSchema() map[string]interface{} { return s.inputSchema }
+
+// TimeoutDuration returns the call timeout of the tool, or fallback if none is set
+func (s *GRPCToolSpec) TimeoutDuration(fallback time.Duration) time.Duration {
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil || timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
+
+// FullMethod returns the method in the "/package.Service/Method" form used by gRPC
+func (s *GRPCToolSpec) FullMethod() string {
+	return "/" + strings.TrimPrefix(s.Method, "/")
+}
+
+// Invoke calls the tool method on its connection
+func (s *GRPCToolSpec) Invoke(ctx context.Context, request, response interface{}) error {
+	if err := s.acquire(); err != nil {
+		return err
+	}
+	defer s.release()
+
+	return s.conn.Invoke(ctx, s.FullMethod(), request, response)
+}
+
+// MethodDescriptor returns the descriptor of the tool method, resolving it
+// through server reflection if that failed at registration
+func (s *GRPCToolSpec) MethodDescriptor(ctx context.Context) (protoreflect.MethodDescriptor, error) {
+	s.mutex.Lock()
+	if s.method != nil {
+		defer s.mutex.Unlock()
+		return s.method, nil
+	}
+
+	method, err := s.resolveMethod(ctx)
+	inputSchema, onResolve := s.inputSchema, s.onResolve
+	s.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if onResolve != nil {
+		onResolve(inputSchema)
+	}
+	return method, nil
+}
+
+// resolveMethod resolves the tool method through server reflection, the caller holds the mutex
+func (s *GRPCToolSpec) resolveMethod(ctx context.Context) (protoreflect.MethodDescriptor, error) {
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	files, err := s.reflectFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.setMethod(files)
+}
+
+// Close closes the client connection of the tool once the calls using it return
+func (s *GRPCToolSpec) Close() error {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.calls == 0 {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// acquire keeps the connection open until release is called
+func (s *GRPCToolSpec) acquire() error {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("connection of gRPC tool %s is closed, the tool was reloaded or removed", s.Method)
+	}
+	s.calls++
+	return nil
+}
+
+// release ends a call using the connection, closing it if the tool was closed meanwhile
+func (s *GRPCToolSpec) release() {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	s.calls--
+	if s.closed && s.calls == 0 {
+		if err := s.conn.Close(); err != nil {
+			log.Printf("⚠️  Failed to close connection of gRPC tool %s: %v", s.Method, err)
+		}
+	}
+}
+
+// loadGRPCTool parses a gRPC tool definition and resolves its method descriptor
+func (r *Registry) loadGRPCTool(filePath string) (interface{}, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gRPC tool definition %s: %w", filePath, err)
+	}
+
+	spec := &GRPCToolSpec{}
+	if err := yaml.Unmarshal(content, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse gRPC tool definition %s: %w", filePath, err)
+	}
+
+	if spec.Target == "" || spec.Method == "" {
+		return nil, fmt.Errorf("gRPC tool definition %s requires target and method", filePath)
+	}
+	if !strings.Contains(strings.TrimPrefix(spec.Method, "/"), "/") {
+		return nil, fmt.Errorf("gRPC tool definition %s has an invalid method %q, expected package.Service/Method", filePath, spec.Method)
+	}
+
+	if spec.DescriptorSet != "" {
+		descriptorPath := spec.DescriptorSet
+		if !filepath.IsAbs(descriptorPath) {
+			descriptorPath = filepath.Join(filepath.Dir(filePath), descriptorPath)
+		}
+
+		files, err := loadDescriptorSet(descriptorPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := spec.setMethod(files); err != nil {
+			return nil, err
+		}
+	}
+
+	spec.conn, err = grpc.NewClient(spec.Target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %s: %w", spec.Target, err)
+	}
+
+	if spec.method == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), grpcResolveTimeout)
+		defer cancel()
+
+		// The server may not be up yet, so resolution is retried on the first call
+		if _, err := spec.MethodDescriptor(ctx); err != nil {
+			log.Printf("⚠️  Failed to resolve gRPC method %s through reflection, retrying on first call: %v", spec.Method, err)
+		}
+	}
+
+	return spec, nil
+}
+
+// setMethod looks up the tool method in the resolved files and derives the input schema
+func (s *GRPCToolSpec) setMethod(files *protoregistry.Files) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, _ := strings.Cut(strings.TrimPrefix(s.Method, "/"), "/")
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("gRPC service %s not found: %w", serviceName, err)
+	}
+
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a gRPC service", serviceName)
+	}
+
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("gRPC method %s not found in service %s", methodName, serviceName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming gRPC method %s is not supported", s.Method)
+	}
+
+	s.method = method
+	s.inputSchema = MessageSchema(method.Input())
+	return method, nil
+}
+
+// reflectFiles fetches the file defining the tool service, and its dependencies,
+// through the server reflection service
+func (s *GRPCToolSpec) reflectFiles(ctx context.Context) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(s.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection unavailable: %w", err)
+	}
+	defer stream.CloseSend()
+
+	serviceName, _, _ := strings.Cut(strings.TrimPrefix(s.Method, "/"), "/")
+
+	fileProtos := map[string]*descriptorpb.FileDescriptorProto{}
+	pending := []*reflectionpb.ServerReflectionRequest{{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	}}
+
+	for len(pending) > 0 {
+		request := pending[0]
+		pending = pending[1:]
+
+		if err := stream.Send(request); err != nil {
+			return nil, fmt.Errorf("server reflection request failed: %w", err)
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("server reflection request failed: %w", err)
+		}
+		if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+			return nil, fmt.Errorf("server reflection error: %s", errorResponse.GetErrorMessage())
+		}
+
+		for _, encoded := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fileProto := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(encoded, fileProto); err != nil {
+				return nil, fmt.Errorf("invalid file descriptor from server reflection: %w", err)
+			}
+			fileProtos[fileProto.GetName()] = fileProto
+		}
+
+		// Request dependencies that were not sent along with the file
+		for _, fileProto := range fileProtos {
+			for _, dependency := range fileProto.GetDependency() {
+				if _, known := fileProtos[dependency]; known || requested(pending, dependency) {
+					continue
+				}
+				if _, err := protoregistry.GlobalFiles.FindFileByPath(dependency); err == nil {
+					continue
+				}
+				pending = append(pending, &reflectionpb.ServerReflectionRequest{
+					MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+				})
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fileProto := range fileProtos {
+		set.File = append(set.File, fileProto)
+	}
+	return newFiles(set)
+}
+
+// requested reports whether a file is already queued for a reflection request
+func requested(pending []*reflectionpb.ServerReflectionRequest, fileName string) bool {
+	for _, request := range pending {
+		if request.GetFileByFilename() == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDescriptorSet reads a serialized FileDescriptorSet, as produced by
+// protoc --descriptor_set_out --include_imports
+func loadDescriptorSet(filePath string) (*protoregistry.Files, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set %s: %w", filePath, err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set %s: %w", filePath, err)
+	}
+
+	return newFiles(set)
+}
+
+// newFiles builds a file registry, resolving well-known imports that are not part of the set
+func newFiles(set *descriptorpb.FileDescriptorSet) (*protoregistry.Files, error) {
+	included := map[string]bool{}
+	for _, fileProto := range set.GetFile() {
+		included[fileProto.GetName()] = true
+	}
+
+	for _, fileProto := range set.GetFile() {
+		for _, dependency := range fileProto.GetDependency() {
+			if included[dependency] {
+				continue
+			}
+			if file, err := protoregistry.GlobalFiles.FindFileByPath(dependency); err == nil {
+				set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+				included[dependency] = true
+			}
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptors: %w", err)
+	}
+	return files, nil
+}
+
+// MessageSchema derives a JSON Schema for the protojson encoding of a message
+func MessageSchema(message protoreflect.MessageDescriptor) map[string]interface{} {
+	return messageSchema(message, map[protoreflect.FullName]bool{})
+}
+
+// messageSchema derives a message schema while guarding against recursive messages
+func messageSchema(message protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) map[string]interface{} {
+	switch message.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration", "google.protobuf.FieldMask":
+		return map[string]interface{}{"type": "string"}
+	case "google.protobuf.Struct":
+		return map[string]interface{}{"type": "object"}
+	case "google.protobuf.Value", "google.protobuf.ListValue", "google.protobuf.Any":
+		return map[string]interface{}{}
+	}
+
+	if visiting[message.FullName()] {
+		return map[string]interface{}{"type": "object"}
+	}
+	visiting[message.FullName()] = true
+	defer delete(visiting, message.FullName())
+
+	properties := map[string]interface{}{}
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+
+		var property map[string]interface{}
+		switch {
+		case field.IsMap():
+			property = map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": fieldSchema(field.MapValue(), visiting),
+			}
+		case field.IsList():
+			property = map[string]interface{}{
+				"type":  "array",
+				"items": fieldSchema(field, visiting),
+			}
+		default:
+			property = fieldSchema(field, visiting)
+		}
+		properties[field.JSONName()] = property
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// fieldSchema derives the schema of a single (non-repeated) field value
+func fieldSchema(field protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) map[string]interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]interface{}{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson encodes 64-bit integers as strings but accepts numbers
+		return map[string]interface{}{"type": []string{"integer", "string"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]string, values.Len())
+		for i := range names {
+			names[i] = string(values.Get(i).Name())
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageSchema(field.Message(), visiting)
+	default:
+		return map[string]interface{}{}
+	}
+}
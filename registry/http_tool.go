@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HTTPToolSpec describes an HTTP proxy tool loaded from a *.http.yaml file.
+// URL and header values are Go templates evaluated against the tool arguments.
+type HTTPToolSpec struct {
+	Description string                 `yaml:"description" json:"description,omitempty"`
+	URL         string                 `yaml:"url" json:"url"`
+	Method      string                 `yaml:"method" json:"method"`
+	Headers     map[string]string      `yaml:"headers" json:"headers,omitempty"`
+	Query       map[string]string      `yaml:"query" json:"query,omitempty"` // query parameter -> argument name
+	Body        map[string]string      `yaml:"body" json:"body,omitempty"`   // body field -> argument name
+	Timeout     string                 `yaml:"timeout" json:"timeout,omitempty"`
+	InputSchema map[string]interface{} `yaml:"input_schema" json:"input_schema,omitempty"`
+}
+
+// TimeoutDuration returns the request timeout of the tool, or fallback if none is set
+func (s *HTTPToolSpec) TimeoutDuration(fallback time.Duration) time.Duration {
+	if s.Timeout == "" {
+		return fallback
+	}
+
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil || timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
+
+func (s *HTTPToolSpec) definedDescription() string                 { return s.Description }
+func (s *HTTPToolSpec) definedInputSchema() map[string]interface{} { return s.InputSchema }
+
+// loadHTTPTool parses and validates an HTTP tool definition file
+func (r *Registry) loadHTTPTool(filePath string) (interface{}, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP tool definition %s: %w", filePath, err)
+	}
+
+	var spec HTTPToolSpec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse HTTP tool definition %s: %w", filePath, err)
+	}
+
+	if spec.URL == "" {
+		return nil, fmt.Errorf("HTTP tool definition %s has no url", filePath)
+	}
+
+	// Templates may only appear in the path and query, so the scheme and host must parse
+	fixed := strings.SplitN(spec.URL, "{{", 2)[0]
+	parsed, err := url.Parse(fixed)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("HTTP tool definition %s has an invalid url: %s", filePath, spec.URL)
+	}
+	if fixed != spec.URL && !strings.ContainsAny(strings.TrimPrefix(fixed, parsed.Scheme+"://"), "/?") {
+		return nil, fmt.Errorf("HTTP tool definition %s has a template in the host of its url: %s", filePath, spec.URL)
+	}
+
+	spec.Method = strings.ToUpper(spec.Method)
+	if spec.Method == "" {
+		spec.Method = "GET"
+	}
+
+	if spec.Timeout != "" {
+		if _, err := time.ParseDuration(spec.Timeout); err != nil {
+			return nil, fmt.Errorf("HTTP tool definition %s has an invalid timeout: %w", filePath, err)
+		}
+	}
+
+	return &spec, nil
+}
@@ -0,0 +1,220 @@
+package registry
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestExtensions are the extensions of tool manifest sidecar files
+var manifestExtensions = []string{".manifest.yaml", ".manifest.yml"}
+
+// ToolManifest holds operational settings of a tool, loaded from a
+// <name>.manifest.yaml sidecar next to the tool file
+type ToolManifest struct {
+	Schedules []ToolSchedule `yaml:"schedules" json:"schedules,omitempty"`
+	Cache     *CacheSettings `yaml:"cache" json:"cache,omitempty"`
+
+	// Settings of tools that run as subprocesses
+	Env        *EnvSettings     `yaml:"env" json:"env,omitempty"`
+	Secrets    []string         `yaml:"secrets" json:"secrets,omitempty"`         // Names of secrets injected as environment variables
+	WorkingDir string           `yaml:"working_dir" json:"working_dir,omitempty"` // Relative to the tool file
+	Sandbox    *SandboxSettings `yaml:"sandbox" json:"sandbox,omitempty"`         // Enables the sandbox for this tool
+	Python     *PythonSettings  `yaml:"python" json:"python,omitempty"`           // Interpreter of Python tools
+}
+
+// ToolSchedule runs a tool periodically with fixed arguments
+type ToolSchedule struct {
+	Name      string                 `yaml:"name" json:"name,omitempty"`
+	Cron      string                 `yaml:"cron" json:"cron"`
+	Arguments map[string]interface{} `yaml:"arguments" json:"arguments,omitempty"`
+}
+
+// CacheSettings opts a deterministic tool into result caching
+type CacheSettings struct {
+	TTL string `yaml:"ttl" json:"ttl"`
+}
+
+// EnvSettings controls the environment of a subprocess tool
+type EnvSettings struct {
+	Allow []string          `yaml:"allow" json:"allow,omitempty"` // Server variables passed through; a trailing * matches a prefix
+	Set   map[string]string `yaml:"set" json:"set,omitempty"`     // Additional variables
+}
+
+// Temporary directory modes of sandboxed subprocesses
+const (
+	SandboxTempPrivate  = "private"  // A fresh writable directory per execution
+	SandboxTempReadOnly = "readonly" // An empty directory the tool cannot write to
+)
+
+// SandboxSettings isolate subprocess tools on Linux. Zero values leave a limit unset.
+type SandboxSettings struct {
+	Enabled        bool    `yaml:"enabled" json:"enabled"`
+	CPUSeconds     uint64  `yaml:"cpu_seconds" json:"cpu_seconds,omitempty"`
+	MemoryMB       uint64  `yaml:"memory_mb" json:"memory_mb,omitempty"` // Address space limit
+	OpenFiles      uint64  `yaml:"open_files" json:"open_files,omitempty"`
+	Processes      uint64  `yaml:"processes" json:"processes,omitempty"` // Counted per user, so best combined with UID
+	UID            *uint32 `yaml:"uid" json:"uid,omitempty"`
+	GID            *uint32 `yaml:"gid" json:"gid,omitempty"`
+	TempDir        string  `yaml:"temp_dir" json:"temp_dir,omitempty"` // "private" or "readonly"
+	NoNetwork      bool    `yaml:"no_network" json:"no_network,omitempty"`
+	MaxOutputBytes int64   `yaml:"max_output_bytes" json:"max_output_bytes,omitempty"` // Per stream; also applies without Enabled
+
+	// Lets tool manifests choose the uid and gid. Only the server settings can
+	// enable it, since a manifest could otherwise run as any user, root included.
+	AllowManifestCredentials bool `yaml:"-" json:"allow_manifest_credentials,omitempty"`
+}
+
+// Merge returns the settings tightened by a manifest sandbox block, which
+// always enables the sandbox. Limits can only be lowered, a temporary
+// directory mode only be chosen when the server sets none, and the uid and
+// gid only be set when the server allows it.
+func (s SandboxSettings) Merge(override *SandboxSettings) (SandboxSettings, error) {
+	if override == nil {
+		return s, nil
+	}
+	if (override.UID != nil || override.GID != nil) && !s.AllowManifestCredentials {
+		return s, fmt.Errorf("sandbox uid and gid may only be set by the server")
+	}
+
+	s.Enabled = true
+	s.CPUSeconds = lowerLimit(s.CPUSeconds, override.CPUSeconds)
+	s.MemoryMB = lowerLimit(s.MemoryMB, override.MemoryMB)
+	s.OpenFiles = lowerLimit(s.OpenFiles, override.OpenFiles)
+	s.Processes = lowerLimit(s.Processes, override.Processes)
+	if override.MaxOutputBytes > 0 && (s.MaxOutputBytes <= 0 || override.MaxOutputBytes < s.MaxOutputBytes) {
+		s.MaxOutputBytes = override.MaxOutputBytes
+	}
+	if override.UID != nil {
+		s.UID = override.UID
+	}
+	if override.GID != nil {
+		s.GID = override.GID
+	}
+	if s.TempDir == "" {
+		s.TempDir = override.TempDir
+	}
+	if override.NoNetwork {
+		s.NoNetwork = true
+	}
+	return s, nil
+}
+
+// lowerLimit returns the lower of two limits, where 0 means unlimited
+func lowerLimit(limit, override uint64) uint64 {
+	if override > 0 && (limit == 0 || override < limit) {
+		return override
+	}
+	return limit
+}
+
+// Validate checks the temporary directory mode
+func (s *SandboxSettings) Validate() error {
+	switch s.TempDir {
+	case "", SandboxTempPrivate, SandboxTempReadOnly:
+		return nil
+	default:
+		return fmt.Errorf("unknown sandbox temp_dir %q, expected %q or %q", s.TempDir, SandboxTempPrivate, SandboxTempReadOnly)
+	}
+}
+
+// TTLDuration returns how long cached results stay valid, or 0 if caching is disabled
+func (c *CacheSettings) TTLDuration() time.Duration {
+	if c == nil {
+		return 0
+	}
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// IsManifest reports whether a file is a tool manifest sidecar
+func IsManifest(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	for _, ext := range manifestExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadManifest reads the manifest of a tool, returning nil if the tool has none.
+// The manifest sits next to the tool file and shares its base name.
+func loadManifest(toolPath string) (*ToolManifest, error) {
+	name := NameFromPath(toolPath)
+	for _, ext := range manifestExtensions {
+		manifestPath := filepath.Join(filepath.Dir(toolPath), name+ext)
+
+		content, err := os.ReadFile(manifestPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", manifestPath, err)
+		}
+
+		var manifest ToolManifest
+		if err := yaml.Unmarshal(content, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", manifestPath, err)
+		}
+
+		for i, schedule := range manifest.Schedules {
+			if schedule.Cron == "" {
+				return nil, fmt.Errorf("schedule %d in manifest %s has no cron expression", i+1, manifestPath)
+			}
+		}
+
+		if manifest.Cache != nil {
+			if _, err := time.ParseDuration(manifest.Cache.TTL); err != nil {
+				return nil, fmt.Errorf("manifest %s has an invalid cache ttl: %w", manifestPath, err)
+			}
+		}
+
+		if manifest.Sandbox != nil {
+			if err := manifest.Sandbox.Validate(); err != nil {
+				return nil, fmt.Errorf("manifest %s: %w", manifestPath, err)
+			}
+		}
+
+		if manifest.WorkingDir != "" && !filepath.IsAbs(manifest.WorkingDir) {
+			manifest.WorkingDir = filepath.Join(filepath.Dir(toolPath), manifest.WorkingDir)
+		}
+
+		return &manifest, nil
+	}
+
+	return nil, nil
+}
+
+// ReloadManifest re-reads the manifest of a file-backed tool after its sidecar changed
+func (r *Registry) ReloadManifest(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tool, exists := r.tools[name]
+	if !exists || tool.FilePath == "" {
+		return nil
+	}
+
+	manifest, err := loadManifest(tool.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// Replace the tool rather than mutating it, since readers hold no lock
+	updated := *tool
+	updated.Manifest = manifest
+	r.resolvePythonEnv(&updated)
+	r.tools[name] = &updated
+
+	log.Printf("📝 Reloaded manifest of MCP tool: %s", name)
+	return nil
+}
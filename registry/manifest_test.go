@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSandboxSettings_Merge(t *testing.T) {
+	uid := uint32(65534)
+	server := SandboxSettings{CPUSeconds: 10, MemoryMB: 256, MaxOutputBytes: 1 << 20, TempDir: SandboxTempReadOnly}
+
+	tests := []struct {
+		name     string
+		settings SandboxSettings
+		override *SandboxSettings
+		want     SandboxSettings
+		wantErr  bool
+	}{
+		{
+			name:     "no sandbox block",
+			settings: server,
+			want:     server,
+		},
+		{
+			name:     "limits are only lowered",
+			settings: server,
+			override: &SandboxSettings{CPUSeconds: 60, MemoryMB: 128, OpenFiles: 32, MaxOutputBytes: 1 << 30},
+			want:     SandboxSettings{Enabled: true, CPUSeconds: 10, MemoryMB: 128, OpenFiles: 32, MaxOutputBytes: 1 << 20, TempDir: SandboxTempReadOnly},
+		},
+		{
+			name:     "temp dir of the server is kept",
+			settings: server,
+			override: &SandboxSettings{TempDir: SandboxTempPrivate, NoNetwork: true},
+			want:     SandboxSettings{Enabled: true, CPUSeconds: 10, MemoryMB: 256, MaxOutputBytes: 1 << 20, TempDir: SandboxTempReadOnly, NoNetwork: true},
+		},
+		{
+			name:     "temp dir without a server default",
+			override: &SandboxSettings{TempDir: SandboxTempPrivate},
+			want:     SandboxSettings{Enabled: true, TempDir: SandboxTempPrivate},
+		},
+		{
+			name:     "credentials refused",
+			settings: server,
+			override: &SandboxSettings{UID: &uid},
+			wantErr:  true,
+		},
+		{
+			name:     "credentials allowed by the server",
+			settings: SandboxSettings{AllowManifestCredentials: true},
+			override: &SandboxSettings{UID: &uid},
+			want:     SandboxSettings{Enabled: true, UID: &uid, AllowManifestCredentials: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.settings.Merge(tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package registry
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"plugin"
+	"strings"
+)
+
+// ModelType represents the type of model
+type ModelType string
+
+const (
+	GoPluginModel ModelType = "go_plugin"
+	PythonModel   ModelType = "python"
+	UnknownModel  ModelType = "unknown"
+)
+
+// ModelToolPrefix is prepended to a model name to form the name of its MCP tool
+const ModelToolPrefix = "model_"
+
+// ModelInfo contains metadata about a registered model. Models implement the
+// Predict contract: a JSON payload in, a JSON result out.
+type ModelInfo struct {
+	Name     string      `json:"name"`
+	FilePath string      `json:"file_path"`
+	Type     ModelType   `json:"type"`
+	Handler  interface{} `json:"-"`
+}
+
+// RegisterModel adds a model to the registry and exposes it as an MCP tool
+func (r *Registry) RegisterModel(name, filePath string) error {
+	if err := checkToolNameFormat(ModelToolPrefix + name); err != nil {
+		return fmt.Errorf("model %s: %w", name, err)
+	}
+	modelType := r.determineModelType(filePath)
+
+	var handler interface{}
+	switch modelType {
+	case GoPluginModel:
+		predict, err := r.loadPredictSymbol(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to load handler for model %s: %w", name, err)
+		}
+		handler = predict
+	case PythonModel:
+		// Python predictors are executed as subprocesses by the handler package
+		handler = filePath
+	default:
+		return fmt.Errorf("unsupported model type for %s", filePath)
+	}
+
+	modelInfo := &ModelInfo{
+		Name:     name,
+		FilePath: filePath,
+		Type:     modelType,
+		Handler:  handler,
+	}
+
+	toolInfo := &ToolInfo{
+		Name:        ModelToolPrefix + name,
+		Description: fmt.Sprintf("Run a prediction with model %s. The arguments are passed to the model as its JSON input.", name),
+		FilePath:    filePath,
+		Type:        ModelTool,
+		InputSchema: r.generateInputSchema(ModelTool),
+		Handler:     modelInfo,
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkToolName(toolInfo); err != nil {
+		return err
+	}
+	if existing, exists := r.tools[toolInfo.Name]; exists {
+		closeHandler(existing)
+	}
+	r.models[name] = modelInfo
+	toolInfo.Registration = r.nextRegistration()
+	r.tools[toolInfo.Name] = toolInfo
+
+	log.Printf("✅ Registered model: %s (%s) at %s", name, modelType, filePath)
+	return nil
+}
+
+// UnregisterModel removes a model and its MCP tool from the registry
+func (r *Registry) UnregisterModel(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.models[name]; exists {
+		delete(r.models, name)
+		if tool, exists := r.tools[ModelToolPrefix+name]; exists && tool.Type == ModelTool {
+			closeHandler(tool)
+			delete(r.tools, tool.Name)
+		}
+		log.Printf("🗑️  Unregistered model: %s", name)
+	}
+}
+
+// GetModel retrieves a model from the registry
+func (r *Registry) GetModel(name string) (*ModelInfo, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	model, exists := r.models[name]
+	return model, exists
+}
+
+// ListModels returns all registered models
+func (r *Registry) ListModels() []*ModelInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	models := make([]*ModelInfo, 0, len(r.models))
+	for _, model := range r.models {
+		models = append(models, model)
+	}
+	return models
+}
+
+// GetModelCount returns the number of registered models
+func (r *Registry) GetModelCount() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.models)
+}
+
+// determineModelType identifies the type of model based on file extension
+func (r *Registry) determineModelType(filePath string) ModelType {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".so":
+		return GoPluginModel
+	case ".py":
+		return PythonModel
+	default:
+		return UnknownModel
+	}
+}
+
+// loadPredictSymbol loads a Go plugin and returns its Predict function
+func (r *Registry) loadPredictSymbol(filePath string) (interface{}, error) {
+	plug, err := plugin.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", filePath, err)
+	}
+
+	predictSymbol, err := plug.Lookup("Predict")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Predict function in plugin %s: %w", filePath, err)
+	}
+
+	return predictSymbol, nil
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry_ModelToolNameCollision(t *testing.T) {
+	reg := NewRegistry()
+	dir := t.TempDir()
+
+	echo := func(ctx context.Context, args struct{}) (string, error) { return "", nil }
+	if err := reg.RegisterFunc("model_taken", "A function tool", echo); err != nil {
+		t.Fatal(err)
+	}
+
+	// A model cannot take over the name of another tool
+	if err := reg.RegisterModel("taken", filepath.Join(dir, "taken.py")); err == nil {
+		t.Error("Expected RegisterModel() to reject a name used by a function tool")
+	}
+	if tool, _ := reg.GetTool("model_taken"); tool.Type != FuncTool {
+		t.Errorf("Expected the function tool to stay registered, got %s", tool.Type)
+	}
+
+	// Nor can another tool take over the name of a model
+	if err := reg.RegisterModel("sentiment", filepath.Join(dir, "sentiment.py")); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterFunc("model_sentiment", "A function tool", echo); err == nil {
+		t.Error("Expected RegisterFunc() to reject a name used by a model")
+	}
+
+	// A model replaces its own tool
+	if err := reg.RegisterModel("sentiment", filepath.Join(dir, "sentiment.py")); err != nil {
+		t.Errorf("RegisterModel() error = %v when re-registering", err)
+	}
+
+	reg.UnregisterModel("taken")
+	if _, exists := reg.GetTool("model_taken"); !exists {
+		t.Error("Expected unregistering an unknown model to leave the function tool in place")
+	}
+}
+
+func TestRegistry_UnregisterToolFile(t *testing.T) {
+	reg := NewRegistry()
+	dir := t.TempDir()
+
+	if err := reg.RegisterModel("sentiment", filepath.Join(dir, "models", "sentiment.py")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tool file named after the model tool is rejected, and removing it
+	// leaves the model tool in place
+	toolPath := filepath.Join(dir, "model_sentiment.py")
+	if err := os.WriteFile(toolPath, []byte("print('{}')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterTool("model_sentiment", toolPath, "A Python tool"); err == nil {
+		t.Fatal("Expected RegisterTool() to reject a name used by a model")
+	}
+	reg.UnregisterToolFile("model_sentiment", toolPath)
+	reg.UnregisterTool("model_sentiment")
+	if tool, exists := reg.GetTool("model_sentiment"); !exists || tool.Type != ModelTool {
+		t.Fatal("Expected the model tool to stay registered")
+	}
+
+	// A tool is removed with the file it was registered from only
+	toolPath = filepath.Join(dir, "echo.py")
+	if err := os.WriteFile(toolPath, []byte("print('{}')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterTool("echo", toolPath, "A Python tool"); err != nil {
+		t.Fatal(err)
+	}
+	reg.UnregisterToolFile("echo", filepath.Join(dir, "other", "echo.py"))
+	if _, exists := reg.GetTool("echo"); !exists {
+		t.Error("Expected a file elsewhere to leave the tool in place")
+	}
+	reg.UnregisterToolFile("echo", toolPath)
+	if _, exists := reg.GetTool("echo"); exists {
+		t.Error("Expected the tool to be removed with its file")
+	}
+}
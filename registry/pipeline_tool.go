@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gin-mcp/jsonpath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineStep is a single step of a pipeline tool. It runs either a registered
+// tool or reads a registered resource.
+type PipelineStep struct {
+	Name      string                 `yaml:"name" json:"name"`
+	Tool      string                 `yaml:"tool" json:"tool,omitempty"`
+	Resource  string                 `yaml:"resource" json:"resource,omitempty"`   // Name or URI
+	Arguments map[string]interface{} `yaml:"arguments" json:"arguments,omitempty"` // argument -> literal or JSONPath
+	When      string                 `yaml:"when" json:"when,omitempty"`           // JSONPath that must be truthy, "!" negates
+}
+
+// PipelineSpec describes a composite tool loaded from a *.pipeline.yaml file.
+// String values starting with "$." are JSONPath expressions evaluated against
+// {"arguments": <pipeline arguments>, "steps": {<step name>: <step output>}}.
+type PipelineSpec struct {
+	Description string                 `yaml:"description" json:"description,omitempty"`
+	InputSchema map[string]interface{} `yaml:"input_schema" json:"input_schema,omitempty"`
+	Steps       []PipelineStep         `yaml:"steps" json:"steps"`
+	Output      interface{}            `yaml:"output" json:"output,omitempty"` // Literal or JSONPath mapping (default: last step output)
+}
+
+func (s *PipelineSpec) definedDescription() string                 { return s.Description }
+func (s *PipelineSpec) definedInputSchema() map[string]interface{} { return s.InputSchema }
+
+// loadPipelineTool parses and validates a pipeline tool definition file. Referenced
+// tools and resources are resolved when the pipeline runs, since they may be
+// registered after the pipeline.
+func (r *Registry) loadPipelineTool(filePath string) (interface{}, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline definition %s: %w", filePath, err)
+	}
+
+	var spec PipelineSpec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline definition %s: %w", filePath, err)
+	}
+
+	if len(spec.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline definition %s has no steps", filePath)
+	}
+
+	names := make(map[string]bool)
+	for i := range spec.Steps {
+		step := &spec.Steps[i]
+
+		if (step.Tool == "") == (step.Resource == "") {
+			return nil, fmt.Errorf("pipeline step %d in %s must reference exactly one tool or resource", i+1, filePath)
+		}
+
+		if step.Name == "" {
+			step.Name = step.Tool + step.Resource
+		}
+		if names[step.Name] {
+			return nil, fmt.Errorf("pipeline definition %s has duplicate step name %s", filePath, step.Name)
+		}
+		names[step.Name] = true
+
+		if step.When != "" {
+			if _, err := jsonpath.Compile(strings.TrimPrefix(step.When, "!")); err != nil {
+				return nil, fmt.Errorf("pipeline step %s in %s has an invalid condition: %w", step.Name, filePath, err)
+			}
+		}
+		if err := validateMapping(step.Arguments); err != nil {
+			return nil, fmt.Errorf("pipeline step %s in %s has invalid arguments: %w", step.Name, filePath, err)
+		}
+	}
+
+	if err := validateMapping(spec.Output); err != nil {
+		return nil, fmt.Errorf("pipeline definition %s has an invalid output: %w", filePath, err)
+	}
+
+	return &spec, nil
+}
+
+// validateMapping compiles every JSONPath expression nested in a mapping
+func validateMapping(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		if jsonpath.IsExpression(v) {
+			_, err := jsonpath.Compile(v)
+			return err
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			if err := validateMapping(item); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := validateMapping(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// DefaultPythonInterpreter runs Python tools that do not configure an interpreter
+const DefaultPythonInterpreter = "python3"
+
+// requirementsFile is the dependency file shared by all Python tools of a directory.
+// A <name>.requirements.txt next to a tool takes precedence.
+const requirementsFile = "requirements.txt"
+
+// ToolStatus tells whether a tool can be executed
+type ToolStatus string
+
+const (
+	ToolReady       ToolStatus = "ready"
+	ToolUnavailable ToolStatus = "unavailable"
+)
+
+// PythonEnv is the interpreter and dependencies of a Python tool
+type PythonEnv struct {
+	Interpreter      string   `json:"interpreter"`
+	Venv             string   `json:"venv,omitempty"`
+	Dependencies     []string `json:"dependencies,omitempty"`
+	DependencySource string   `json:"dependency_source,omitempty"` // Requirements file, or "inline" for script metadata
+}
+
+// PythonSettings select the interpreter of a Python tool in its manifest
+type PythonSettings struct {
+	Interpreter string `yaml:"interpreter" json:"interpreter,omitempty"` // Executable name or path, relative to the tool file
+	Venv        string `yaml:"venv" json:"venv,omitempty"`               // Virtualenv directory, relative to the tool file
+}
+
+// inlineMetadataPattern matches an inline script metadata block (PEP 723)
+var inlineMetadataPattern = regexp.MustCompile(`(?m)^# /// script[ \t]*$((?:\n#(?: .*)?$)*?)\n# ///[ \t]*$`)
+
+// dependenciesPattern matches the start of the dependencies array in inline metadata
+var dependenciesPattern = regexp.MustCompile(`(?m)^dependencies\s*=\s*\[`)
+
+// quotedPattern matches the quoted strings of a TOML array
+var quotedPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'([^']*)'`)
+
+// SetPythonEnvsDir sets the directory holding pre-built virtualenvs of tools that
+// declare dependencies. Tools registered afterwards look up their environment there.
+func (r *Registry) SetPythonEnvsDir(dir string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pythonEnvsDir = dir
+}
+
+// IsRequirements reports whether a file declares dependencies of Python tools
+func IsRequirements(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Base(filePath)), requirementsFile)
+}
+
+// ReloadPythonEnvs re-resolves the environments of the Python tools in a
+// directory after a requirements file changed
+func (r *Registry) ReloadPythonEnvs(dir string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for name, tool := range r.tools {
+		if tool.Type != PythonTool || filepath.Dir(tool.FilePath) != filepath.Clean(dir) {
+			continue
+		}
+
+		// Replace the tool rather than mutating it, since readers hold no lock
+		updated := *tool
+		r.resolvePythonEnv(&updated)
+		r.tools[name] = &updated
+	}
+}
+
+// resolvePythonEnv sets the interpreter and status of a Python tool. A missing
+// interpreter does not fail registration, it marks the tool unavailable.
+func (r *Registry) resolvePythonEnv(toolInfo *ToolInfo) {
+	toolInfo.Status, toolInfo.StatusError = ToolReady, ""
+	if toolInfo.Type != PythonTool {
+		return
+	}
+
+	env := &PythonEnv{Interpreter: DefaultPythonInterpreter}
+	toolInfo.Python = env
+
+	dependencies, source, err := detectDependencies(toolInfo.FilePath)
+	if err != nil {
+		toolInfo.Status, toolInfo.StatusError = ToolUnavailable, err.Error()
+		return
+	}
+	env.Dependencies, env.DependencySource = dependencies, source
+
+	var settings PythonSettings
+	if toolInfo.Manifest != nil && toolInfo.Manifest.Python != nil {
+		settings = *toolInfo.Manifest.Python
+	}
+
+	hint := ""
+	switch {
+	case settings.Interpreter != "":
+		env.Interpreter = settings.Interpreter
+		if strings.ContainsRune(env.Interpreter, '/') && !filepath.IsAbs(env.Interpreter) {
+			env.Interpreter = filepath.Join(filepath.Dir(toolInfo.FilePath), env.Interpreter)
+		}
+	case settings.Venv != "":
+		env.Venv = settings.Venv
+		if !filepath.IsAbs(env.Venv) {
+			env.Venv = filepath.Join(filepath.Dir(toolInfo.FilePath), env.Venv)
+		}
+		env.Interpreter = venvInterpreter(env.Venv)
+	case len(dependencies) > 0 && r.pythonEnvsDir != "":
+		env.Venv = filepath.Join(r.pythonEnvsDir, strings.ReplaceAll(toolInfo.Name, "/", "_")+"-"+dependencyHash(dependencies))
+		env.Interpreter = venvInterpreter(env.Venv)
+		hint = fmt.Sprintf(" (build it with: python3 -m venv %s && %s -m pip install %s)",
+			env.Venv, env.Interpreter, strings.Join(quoteAll(dependencies), " "))
+	case len(dependencies) > 0:
+		log.Printf("⚠️  Python tool %s declares dependencies in %s but has no virtualenv, using %s", toolInfo.Name, source, env.Interpreter)
+	}
+
+	if _, err := exec.LookPath(env.Interpreter); err != nil {
+		toolInfo.Status = ToolUnavailable
+		toolInfo.StatusError = fmt.Sprintf("Python interpreter %s not found%s", env.Interpreter, hint)
+		log.Printf("⚠️  Python tool %s is unavailable: %s", toolInfo.Name, toolInfo.StatusError)
+	}
+}
+
+// detectDependencies returns the dependencies of a Python tool from its inline
+// script metadata, its own requirements file or the one of its directory
+func detectDependencies(filePath string) ([]string, string, error) {
+	script, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read tool file %s: %w", filePath, err)
+	}
+	if dependencies, found := inlineDependencies(script); found {
+		return dependencies, "inline", nil
+	}
+
+	dir, name := filepath.Dir(filePath), NameFromPath(filePath)
+	for _, candidate := range []string{filepath.Join(dir, name+"."+requirementsFile), filepath.Join(dir, requirementsFile)} {
+		content, err := os.ReadFile(candidate)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read requirements %s: %w", candidate, err)
+		}
+		return parseRequirements(content), candidate, nil
+	}
+
+	return nil, "", nil
+}
+
+// inlineDependencies extracts the dependencies array of an inline script metadata block
+func inlineDependencies(script []byte) ([]string, bool) {
+	match := inlineMetadataPattern.FindSubmatch(script)
+	if match == nil {
+		return nil, false
+	}
+
+	// Strip the comment prefixes to get the TOML document
+	var document strings.Builder
+	for _, line := range strings.Split(string(match[1]), "\n") {
+		line = strings.TrimPrefix(line, "#")
+		document.WriteString(strings.TrimPrefix(line, " "))
+		document.WriteString("\n")
+	}
+	toml := document.String()
+
+	key := dependenciesPattern.FindStringIndex(toml)
+	if key == nil {
+		return nil, true
+	}
+	// Find the closing bracket outside of strings, extras like "pkg[cli]" contain brackets
+	array, quote := toml[key[1]:], byte(0)
+	end := strings.IndexFunc(array, func(c rune) bool {
+		switch {
+		case quote != 0 && byte(c) == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = byte(c)
+		case quote == 0 && c == ']':
+			return true
+		}
+		return false
+	})
+	if end < 0 {
+		return nil, true
+	}
+
+	var dependencies []string
+	for _, quoted := range quotedPattern.FindAllStringSubmatch(array[:end], -1) {
+		dependencies = append(dependencies, quoted[1]+quoted[2])
+	}
+	return dependencies, true
+}
+
+// parseRequirements returns the requirement lines of a requirements file
+func parseRequirements(content []byte) []string {
+	var requirements []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, " #"); comment >= 0 {
+			line = line[:comment]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		requirements = append(requirements, line)
+	}
+	return requirements
+}
+
+// dependencyHash identifies a set of dependencies, so that changed requirements
+// point to a new virtualenv
+func dependencyHash(dependencies []string) string {
+	hash := sha256.Sum256([]byte(strings.Join(dependencies, "\n")))
+	return hex.EncodeToString(hash[:6])
+}
+
+// venvInterpreter returns the Python executable of a virtualenv
+func venvInterpreter(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+	return filepath.Join(venv, "bin", "python")
+}
+
+// quoteAll quotes requirements for a shell command line
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	}
+	return quoted
+}
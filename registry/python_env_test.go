@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const inlineScript = `# /// script
+# requires-python = ">=3.10"
+# dependencies = [
+#   "requests<3",
+#   'rich[jupyter]',
+# ]
+# ///
+print("hello")
+`
+
+func TestRegistry_PythonEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("virtualenv layout differs on Windows")
+	}
+	if _, err := exec.LookPath(DefaultPythonInterpreter); err != nil {
+		t.Skip("python3 not available")
+	}
+
+	tests := []struct {
+		name             string
+		script           string
+		files            map[string]string
+		envsDir          bool
+		wantDependencies []string
+		wantSource       string
+		wantInterpreter  string // Relative to the tool directory unless it has no slash
+		wantStatus       ToolStatus
+		wantError        string
+	}{
+		{
+			name:            "system interpreter",
+			script:          "print('hello')\n",
+			wantInterpreter: DefaultPythonInterpreter,
+			wantStatus:      ToolReady,
+		},
+		{
+			name:             "inline metadata",
+			script:           inlineScript,
+			wantDependencies: []string{"requests<3", "rich[jupyter]"},
+			wantSource:       "inline",
+			wantInterpreter:  DefaultPythonInterpreter,
+			wantStatus:       ToolReady,
+		},
+		{
+			name:             "tool requirements take precedence",
+			script:           "print('hello')\n",
+			files:            map[string]string{"requirements.txt": "flask\n", "tool.requirements.txt": "# pinned\nnumpy==1.26 # fast\n\npandas\n"},
+			wantDependencies: []string{"numpy==1.26", "pandas"},
+			wantSource:       "tool.requirements.txt",
+			wantInterpreter:  DefaultPythonInterpreter,
+			wantStatus:       ToolReady,
+		},
+		{
+			name:            "manifest virtualenv",
+			script:          "print('hello')\n",
+			files:           map[string]string{"tool.manifest.yaml": "python:\n  venv: .venv\n", ".venv/bin/python": "#!/bin/sh\n"},
+			wantInterpreter: ".venv/bin/python",
+			wantStatus:      ToolReady,
+		},
+		{
+			name:            "missing manifest interpreter",
+			script:          "print('hello')\n",
+			files:           map[string]string{"tool.manifest.yaml": "python:\n  interpreter: python2.4-missing\n"},
+			wantInterpreter: "python2.4-missing",
+			wantStatus:      ToolUnavailable,
+			wantError:       "Python interpreter python2.4-missing not found",
+		},
+		{
+			name:             "virtualenv not built in cache directory",
+			script:           "print('hello')\n",
+			files:            map[string]string{"requirements.txt": "requests\n"},
+			envsDir:          true,
+			wantDependencies: []string{"requests"},
+			wantSource:       "requirements.txt",
+			wantStatus:       ToolUnavailable,
+			wantError:        "python3 -m venv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			toolPath := filepath.Join(dir, "tool.py")
+			if err := os.WriteFile(toolPath, []byte(tt.script), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			reg := NewRegistry()
+			if tt.envsDir {
+				reg.SetPythonEnvsDir(filepath.Join(dir, "envs"))
+			}
+			if err := reg.RegisterTool("tool", toolPath, "tool"); err != nil {
+				t.Fatalf("RegisterTool() error = %v", err)
+			}
+			tool, _ := reg.GetTool("tool")
+
+			if !reflect.DeepEqual(tool.Python.Dependencies, tt.wantDependencies) {
+				t.Errorf("Dependencies = %v, want %v", tool.Python.Dependencies, tt.wantDependencies)
+			}
+			if tt.wantSource != "" && filepath.Base(tool.Python.DependencySource) != tt.wantSource {
+				t.Errorf("DependencySource = %s, want %s", tool.Python.DependencySource, tt.wantSource)
+			}
+
+			wantInterpreter := tt.wantInterpreter
+			if strings.Contains(wantInterpreter, "/") {
+				wantInterpreter = filepath.Join(dir, wantInterpreter)
+			}
+			if wantInterpreter != "" && tool.Python.Interpreter != wantInterpreter {
+				t.Errorf("Interpreter = %s, want %s", tool.Python.Interpreter, wantInterpreter)
+			}
+
+			if tool.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", tool.Status, tt.wantStatus)
+			}
+			if !strings.Contains(tool.StatusError, tt.wantError) {
+				t.Errorf("StatusError = %q, want it to contain %q", tool.StatusError, tt.wantError)
+			}
+		})
+	}
+}
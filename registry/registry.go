@@ -1,12 +1,19 @@
 package registry
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"mime"
+	"net/url"
+	"os"
 	"path/filepath"
 	"plugin"
+	"regexp"
 	"strings"
 	"sync"
 )
@@ -27,15 +34,55 @@ type ToolType string
 const (
 	GoPluginTool ToolType = "go_plugin"
 	PythonTool   ToolType = "python"
+	HTTPTool     ToolType = "http"
+	GRPCTool     ToolType = "grpc"
+	RouteTool    ToolType = "gin_route"
+	FuncTool     ToolType = "go_func"
+	BuiltinTool  ToolType = "builtin" // In-process tool provided by the server, its name is reserved
+	ModelTool    ToolType = "model"
+	PipelineTool ToolType = "pipeline"
 	UnknownTool  ToolType = "unknown"
 )
 
+// ToolFunc is the handler of a tool that runs inside the server process
+type ToolFunc func(ctx context.Context, input []byte) ([]byte, error)
+
+// definitionToolTypes maps the multi-part extensions of tool definition files to their tool type
+var definitionToolTypes = map[string]ToolType{
+	".http.yaml": HTTPTool,
+	".http.yml":  HTTPTool,
+	".grpc.yaml": GRPCTool,
+	".grpc.yml":  GRPCTool,
+
+	".pipeline.yaml": PipelineTool,
+	".pipeline.yml":  PipelineTool,
+}
+
+// toolDefinition is implemented by handlers loaded from definition files that
+// carry their own description and input schema
+type toolDefinition interface {
+	definedDescription() string
+	definedInputSchema() map[string]interface{}
+}
+
+// DefaultResourceURIBase is prepended to the paths of resources relative to the resources directory
+const DefaultResourceURIBase = "file:///"
+
 // ResourceInfo contains metadata about a registered MCP resource
 type ResourceInfo struct {
 	Name     string       `json:"name"`
-	FilePath string       `json:"file_path"`
+	URI      string       `json:"uri"`
+	FilePath string       `json:"-"` // Not exported, to keep the server layout private
 	Type     ResourceType `json:"type"`
 	MimeType string       `json:"mime_type"`
+
+	Title       string               `json:"title,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Size        int64                `json:"size,omitempty"` // Bytes of file content
+	Annotations *ResourceAnnotations `json:"annotations,omitempty"`
+	API         *APIResourceSpec     `json:"api,omitempty"` // Set for API resources
+
+	Registration uint64 `json:"-"` // Distinguishes registrations, kept when metadata is reloaded
 }
 
 // ToolInfo contains metadata about a registered MCP tool
@@ -46,13 +93,34 @@ type ToolInfo struct {
 	Type        ToolType               `json:"type"`
 	InputSchema map[string]interface{} `json:"input_schema"`
 	Handler     interface{}            `json:"-"`
+
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+	Manifest     *ToolManifest          `json:"manifest,omitempty"`
+	FileHash     string                 `json:"file_hash,omitempty"` // SHA-256 of the tool file
+	Python       *PythonEnv             `json:"python,omitempty"`
+	Status       ToolStatus             `json:"status,omitempty"`
+	StatusError  string                 `json:"status_error,omitempty"` // Why the tool is unavailable
+	Registration uint64                 `json:"-"`                      // Distinguishes registrations, kept when the manifest or schema is reloaded
 }
 
 // Registry manages the collection of available MCP resources and tools
 type Registry struct {
 	resources map[string]*ResourceInfo
 	tools     map[string]*ToolInfo
+	models    map[string]*ModelInfo
 	mutex     sync.RWMutex
+
+	registrations uint64 // Last registration number handed out
+
+	pythonEnvsDir string // Pre-built virtualenvs of Python tools with dependencies
+
+	secretLookup func(name string) (string, bool) // Resolves secrets referenced by API resources
+
+	resourceChanged func(name string, resource *ResourceInfo) // Called after a resource is registered, updated or removed
+
+	uris            map[string]string // Resource names by URI
+	resourcesDir    string            // Directory resource URIs are relative to
+	resourceURIBase string
 }
 
 // NewRegistry creates a new MCP registry
@@ -60,11 +128,85 @@ func NewRegistry() *Registry {
 	return &Registry{
 		resources: make(map[string]*ResourceInfo),
 		tools:     make(map[string]*ToolInfo),
+		models:    make(map[string]*ModelInfo),
+		uris:      make(map[string]string),
+	}
+}
+
+// SetResourceURIs sets the directory resource URIs are relative to and the base
+// they are appended to, such as "mcp://project/". A base without a trailing
+// slash gets one. It applies to resources registered afterwards.
+func (r *Registry) SetResourceURIs(resourcesDir, base string) error {
+	if base != "" {
+		parsed, err := url.Parse(base)
+		if err != nil {
+			return fmt.Errorf("invalid resource URI base %q: %w", base, err)
+		}
+		if parsed.Scheme == "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+			return fmt.Errorf("invalid resource URI base %q: expected a scheme and no query or fragment", base)
+		}
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.resourcesDir = resourcesDir
+	r.resourceURIBase = base
+	return nil
+}
+
+// SetSecretLookup sets the resolver of secrets that API resource headers
+// reference. Resources referencing a secret it does not know fail to register.
+func (r *Registry) SetSecretLookup(lookup func(name string) (string, bool)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.secretLookup = lookup
+}
+
+// lookupSecret resolves a secret, none are known without a resolver. The
+// caller holds the lock.
+func (r *Registry) lookupSecret(name string) (string, bool) {
+	if r.secretLookup == nil {
+		return "", false
+	}
+	return r.secretLookup(name)
+}
+
+// OnResourceChange sets a function called with each resource registered or
+// updated, and with a nil resource when one is removed. It runs outside the
+// registry lock, so it may use the registry.
+func (r *Registry) OnResourceChange(fn func(name string, resource *ResourceInfo)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.resourceChanged = fn
+}
+
+// notifyResourceChange calls the resource change function, if any. The
+// caller does not hold the lock.
+func (r *Registry) notifyResourceChange(name string, resource *ResourceInfo) {
+	r.mutex.RLock()
+	fn := r.resourceChanged
+	r.mutex.RUnlock()
+
+	if fn != nil {
+		fn(name, resource)
 	}
 }
 
 // RegisterResource adds a resource to the registry
 func (r *Registry) RegisterResource(name, filePath string) error {
+	resourceInfo, err := r.registerResource(name, filePath)
+	if err != nil {
+		return err
+	}
+	r.notifyResourceChange(name, resourceInfo)
+	return nil
+}
+
+// registerResource adds a resource to the registry and returns it
+func (r *Registry) registerResource(name, filePath string) (*ResourceInfo, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -78,17 +220,39 @@ func (r *Registry) RegisterResource(name, filePath string) error {
 		MimeType: mimeType,
 	}
 
+	if resourceType == APIRResource {
+		spec, err := loadAPIResource(filePath, r.lookupSecret)
+		if err != nil {
+			return nil, err
+		}
+		resourceInfo.API = spec
+		resourceInfo.MimeType = spec.MimeType
+	}
+	applyResourceMetadata(resourceInfo)
+
+	resourceInfo.URI = r.resourceURI(resourceInfo)
+	if owner, exists := r.uris[resourceInfo.URI]; exists && owner != name {
+		return nil, fmt.Errorf("URI %s of resource %s is already used by resource %s", resourceInfo.URI, name, owner)
+	}
+	if previous, exists := r.resources[name]; exists {
+		delete(r.uris, previous.URI)
+	}
+	r.uris[resourceInfo.URI] = name
+
+	resourceInfo.Registration = r.nextRegistration()
 	r.resources[name] = resourceInfo
 
 	log.Printf("✅ Registered MCP resource: %s (%s) at %s", name, resourceType, filePath)
-	return nil
+	return resourceInfo, nil
 }
 
-// RegisterTool adds a tool to the registry
+// RegisterTool adds a tool to the registry. The handler is loaded without
+// holding the registry lock, since loading may wait on the network, such as
+// for gRPC server reflection.
 func (r *Registry) RegisterTool(name, filePath, description string) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
+	if err := checkToolNameFormat(name); err != nil {
+		return err
+	}
 	toolType := r.determineToolType(filePath)
 
 	toolInfo := &ToolInfo{
@@ -104,36 +268,188 @@ func (r *Registry) RegisterTool(name, filePath, description string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load handler for tool %s: %w", name, err)
 	}
-
 	toolInfo.Handler = handler
+
+	// Definition files may override the generated description and schema
+	if definition, ok := handler.(toolDefinition); ok {
+		if description := definition.definedDescription(); description != "" {
+			toolInfo.Description = description
+		}
+		if inputSchema := definition.definedInputSchema(); inputSchema != nil {
+			toolInfo.InputSchema = inputSchema
+		}
+	}
+
+	// Methods resolved on the first call update the advertised schema
+	if spec, ok := handler.(*GRPCToolSpec); ok {
+		spec.onResolve = func(inputSchema map[string]interface{}) {
+			r.refreshInputSchema(name, spec, inputSchema)
+		}
+	}
+
+	manifest, err := loadManifest(filePath)
+	if err != nil {
+		closeHandler(toolInfo)
+		return fmt.Errorf("failed to load manifest for tool %s: %w", name, err)
+	}
+	toolInfo.Manifest = manifest
+
+	fileHash, err := hashFile(filePath)
+	if err != nil {
+		closeHandler(toolInfo)
+		return fmt.Errorf("failed to hash tool file %s: %w", filePath, err)
+	}
+	toolInfo.FileHash = fileHash
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkToolName(toolInfo); err != nil {
+		closeHandler(toolInfo)
+		return err
+	}
+	r.resolvePythonEnv(toolInfo)
+	if existing, exists := r.tools[name]; exists {
+		closeHandler(existing)
+	}
+	toolInfo.Registration = r.nextRegistration()
 	r.tools[name] = toolInfo
 
 	log.Printf("✅ Registered MCP tool: %s (%s) at %s", name, toolType, filePath)
 	return nil
 }
 
-// UnregisterResource removes a resource from the registry
-func (r *Registry) UnregisterResource(name string) {
+// checkToolName rejects a tool whose name is taken by the tool of a model or
+// by a built-in tool, or a model or built-in tool whose name is taken by
+// another tool. Tools of the same kind replace each other. The caller holds
+// the lock.
+func (r *Registry) checkToolName(toolInfo *ToolInfo) error {
+	existing, exists := r.tools[toolInfo.Name]
+	if !exists || toolKind(existing.Type) == toolKind(toolInfo.Type) {
+		return nil
+	}
+	switch existing.Type {
+	case ModelTool:
+		return fmt.Errorf("tool name %s is already used by a model", toolInfo.Name)
+	case BuiltinTool:
+		return fmt.Errorf("tool name %s is reserved by a built-in tool", toolInfo.Name)
+	}
+	return fmt.Errorf("tool name %s of a %s tool is already used by a %s tool", toolInfo.Name, toolInfo.Type, existing.Type)
+}
+
+// toolKind groups the tool types that may replace each other: the tools of
+// models, built-in tools, and all other tools
+func toolKind(toolType ToolType) ToolType {
+	if toolType == ModelTool || toolType == BuiltinTool {
+		return toolType
+	}
+	return ""
+}
+
+// refreshInputSchema advertises the input schema a tool definition resolved
+// after registration, unless the tool was replaced in the meantime
+func (r *Registry) refreshInputSchema(name string, handler interface{}, inputSchema map[string]interface{}) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, exists := r.resources[name]; exists {
+	tool, exists := r.tools[name]
+	if !exists || tool.Handler != handler {
+		return
+	}
+
+	// Replace the tool rather than mutating it, since readers hold no lock
+	updated := *tool
+	updated.InputSchema = inputSchema
+	r.tools[name] = &updated
+}
+
+// RegisterToolInfo adds a tool that is not backed by a file, such as an
+// in-process ToolFunc. The tool must carry its own handler.
+func (r *Registry) RegisterToolInfo(toolInfo *ToolInfo) error {
+	if err := checkToolNameFormat(toolInfo.Name); err != nil {
+		return err
+	}
+	if toolInfo.Handler == nil {
+		return fmt.Errorf("no handler provided for tool %s", toolInfo.Name)
+	}
+	if toolInfo.InputSchema == nil {
+		toolInfo.InputSchema = r.generateInputSchema(toolInfo.Type)
+	}
+	if toolInfo.Status == "" {
+		toolInfo.Status = ToolReady
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkToolName(toolInfo); err != nil {
+		return err
+	}
+	if existing, exists := r.tools[toolInfo.Name]; exists {
+		closeHandler(existing)
+	}
+	toolInfo.Registration = r.nextRegistration()
+	r.tools[toolInfo.Name] = toolInfo
+
+	log.Printf("✅ Registered MCP tool: %s (%s)", toolInfo.Name, toolInfo.Type)
+	return nil
+}
+
+// nextRegistration numbers a registration. State that handlers keep per tool
+// or resource, such as cached results, is dropped when the number changes,
+// while replacing a copy for a reloaded manifest or metadata keeps it. The
+// caller holds the lock.
+func (r *Registry) nextRegistration() uint64 {
+	r.registrations++
+	return r.registrations
+}
+
+// UnregisterResource removes a resource from the registry
+func (r *Registry) UnregisterResource(name string) {
+	r.mutex.Lock()
+	resource, exists := r.resources[name]
+	if exists {
+		delete(r.uris, resource.URI)
 		delete(r.resources, name)
+	}
+	r.mutex.Unlock()
+
+	if exists {
 		log.Printf("🗑️  Unregistered MCP resource: %s", name)
+		r.notifyResourceChange(name, nil)
 	}
 }
 
-// UnregisterTool removes a tool from the registry
+// UnregisterTool removes a tool from the registry. The tools of models, which
+// go with UnregisterModel, and built-in tools are left alone.
 func (r *Registry) UnregisterTool(name string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, exists := r.tools[name]; exists {
-		delete(r.tools, name)
-		log.Printf("🗑️  Unregistered MCP tool: %s", name)
+	if tool, exists := r.tools[name]; exists && toolKind(tool.Type) == "" {
+		r.unregisterTool(tool)
 	}
 }
 
+// UnregisterToolFile removes a tool if it was registered from filePath, so
+// that removing a file does not take a model or in-process tool of the same
+// name with it
+func (r *Registry) UnregisterToolFile(name, filePath string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if tool, exists := r.tools[name]; exists && tool.Type != ModelTool && tool.FilePath == filePath {
+		r.unregisterTool(tool)
+	}
+}
+
+// unregisterTool removes a registered tool, the caller holds the lock
+func (r *Registry) unregisterTool(tool *ToolInfo) {
+	closeHandler(tool)
+	delete(r.tools, tool.Name)
+	log.Printf("🗑️  Unregistered MCP tool: %s", tool.Name)
+}
+
 // GetResource retrieves a resource from the registry
 func (r *Registry) GetResource(name string) (*ResourceInfo, bool) {
 	r.mutex.RLock()
@@ -143,6 +459,41 @@ func (r *Registry) GetResource(name string) (*ResourceInfo, bool) {
 	return resource, exists
 }
 
+// GetResourceByURI retrieves a resource from the registry by its URI
+func (r *Registry) GetResourceByURI(uri string) (*ResourceInfo, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	name, exists := r.uris[uri]
+	if !exists {
+		return nil, false
+	}
+	resource, exists := r.resources[name]
+	return resource, exists
+}
+
+// resourceURI builds the URI of a resource from its path relative to the
+// resources directory, or from its name for API resources which have no
+// content file
+func (r *Registry) resourceURI(resourceInfo *ResourceInfo) string {
+	base := r.resourceURIBase
+	if base == "" {
+		base = DefaultResourceURIBase
+	}
+
+	path := resourceInfo.Name
+	if resourceInfo.Type != APIRResource {
+		path = filepath.Base(resourceInfo.FilePath)
+		if r.resourcesDir != "" {
+			if rel, err := filepath.Rel(r.resourcesDir, resourceInfo.FilePath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				path = filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	return base + (&url.URL{Path: path}).EscapedPath()
+}
+
 // GetTool retrieves a tool from the registry
 func (r *Registry) GetTool(name string) (*ToolInfo, bool) {
 	r.mutex.RLock()
@@ -178,6 +529,10 @@ func (r *Registry) ListTools() []*ToolInfo {
 
 // determineResourceType identifies the type of resource based on file extension
 func (r *Registry) determineResourceType(filePath string) ResourceType {
+	if isAPIResource(filePath) {
+		return APIRResource
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	switch ext {
@@ -192,8 +547,67 @@ func (r *Registry) determineResourceType(filePath string) ResourceType {
 	}
 }
 
+// NameFromPath derives the registry name of a file by stripping its extension,
+// including multi-part extensions such as ".http.yaml"
+func NameFromPath(filePath string) string {
+	base := filepath.Base(filePath)
+	lower := strings.ToLower(base)
+
+	for ext := range definitionToolTypes {
+		if strings.HasSuffix(lower, ext) {
+			return base[:len(base)-len(ext)]
+		}
+	}
+	for _, ext := range append(manifestExtensions, apiResourceExtensions...) {
+		if strings.HasSuffix(lower, ext) {
+			return base[:len(base)-len(ext)]
+		}
+	}
+
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// RelativeName derives the registry name of a file below a root directory from
+// its relative path, so that docs/api.md and specs/api.md become "docs/api" and
+// "specs/api". Names always use forward slashes.
+func RelativeName(root, filePath string) string {
+	name := NameFromPath(filePath)
+
+	dir, err := filepath.Rel(root, filepath.Dir(filePath))
+	if err != nil || dir == "." || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return name
+	}
+	return filepath.ToSlash(dir) + "/" + name
+}
+
+// RelativeToolName derives the name of a tool or model from its path below a
+// root directory like RelativeName, joining directories with "_" rather than
+// "/", which MCP tool names may not contain: tools/github/issues.py becomes
+// "github_issues".
+func RelativeToolName(root, filePath string) string {
+	return strings.ReplaceAll(RelativeName(root, filePath), "/", "_")
+}
+
+// toolNamePattern matches the tool names MCP clients accept
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// checkToolNameFormat rejects tool names MCP clients would not accept
+func checkToolNameFormat(name string) error {
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid tool name %q: use at most 64 letters, digits, _ or -", name)
+	}
+	return nil
+}
+
 // determineToolType identifies the type of tool based on file extension
 func (r *Registry) determineToolType(filePath string) ToolType {
+	lower := strings.ToLower(filePath)
+	for ext, toolType := range definitionToolTypes {
+		if strings.HasSuffix(lower, ext) {
+			return toolType
+		}
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	switch ext {
@@ -230,6 +644,32 @@ func (r *Registry) determineMimeType(filePath string) string {
 	}
 }
 
+// textMimeTypes are non-text/* types whose content is text
+var textMimeTypes = map[string]bool{
+	"application/json":         true,
+	"application/xml":          true,
+	"application/x-yaml":       true,
+	"application/yaml":         true,
+	"application/javascript":   true,
+	"application/x-javascript": true,
+	"application/sql":          true,
+	"application/x-sh":         true,
+	"application/toml":         true,
+}
+
+// IsTextMimeType reports whether content of a MIME type is text
+func IsTextMimeType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	}
+
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		textMimeTypes[mediaType]
+}
+
 // generateInputSchema generates a basic input schema for tools
 func (r *Registry) generateInputSchema(toolType ToolType) map[string]interface{} {
 	switch toolType {
@@ -273,11 +713,41 @@ func (r *Registry) loadToolHandler(toolInfo *ToolInfo) (interface{}, error) {
 		return r.loadGoPlugin(toolInfo.FilePath)
 	case PythonTool:
 		return r.loadPythonScript(toolInfo.FilePath)
+	case HTTPTool:
+		return r.loadHTTPTool(toolInfo.FilePath)
+	case GRPCTool:
+		return r.loadGRPCTool(toolInfo.FilePath)
+	case PipelineTool:
+		return r.loadPipelineTool(toolInfo.FilePath)
 	default:
 		return nil, fmt.Errorf("unsupported tool type: %s", toolInfo.Type)
 	}
 }
 
+// closeHandler releases resources held by a tool handler, such as client connections
+func closeHandler(toolInfo *ToolInfo) {
+	if closer, ok := toolInfo.Handler.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("⚠️  Failed to close handler of tool %s: %v", toolInfo.Name, err)
+		}
+	}
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file's contents
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 // loadGoPlugin loads a Go plugin and returns the Execute function
 func (r *Registry) loadGoPlugin(filePath string) (interface{}, error) {
 	plug, err := plugin.Open(filePath)
@@ -346,9 +816,11 @@ func (r *Registry) ExportRegistry() ([]byte, error) {
 	export := map[string]interface{}{
 		"resources": r.resources,
 		"tools":     r.tools,
+		"models":    r.models,
 		"counts": map[string]int{
 			"resources": len(r.resources),
 			"tools":     len(r.tools),
+			"models":    len(r.models),
 		},
 	}
 
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRelativeToolName(t *testing.T) {
+	root := filepath.FromSlash("/srv/tools")
+
+	tests := []struct {
+		path         string
+		wantResource string
+		wantTool     string
+	}{
+		{"/srv/tools/search.py", "search", "search"},
+		{"/srv/tools/github/issues.http.yaml", "github/issues", "github_issues"},
+		{"/srv/tools/github/v2/issues.py", "github/v2/issues", "github_v2_issues"},
+		{"/elsewhere/issues.py", "issues", "issues"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path := filepath.FromSlash(tt.path)
+			if got := RelativeName(root, path); got != tt.wantResource {
+				t.Errorf("RelativeName() = %q, want %q", got, tt.wantResource)
+			}
+			if got := RelativeToolName(root, path); got != tt.wantTool {
+				t.Errorf("RelativeToolName() = %q, want %q", got, tt.wantTool)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterToolName(t *testing.T) {
+	toolPath := filepath.Join(t.TempDir(), "echo.py")
+	if err := os.WriteFile(toolPath, []byte("print('{}')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"echo-tool_2", false},
+		{"github/echo", true},
+		{"echo tool", true},
+		{"", true},
+		{strings.Repeat("a", 65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			err := r.RegisterTool(tt.name, toolPath, "echo")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterTool(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+
+			// Tools registered in-process follow the same rules
+			echo := func(ctx context.Context, args struct{}) (string, error) { return "", nil }
+			err = r.RegisterFunc(tt.name, "echo", echo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterFunc(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewRegistry().RegisterModel("nested/model", toolPath); err == nil {
+		t.Error("Expected a model name with a slash to be rejected")
+	}
+}
+
+func TestRegistry_SetResourceURIs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docs", "api.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# API"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		base    string
+		wantURI string
+		wantErr bool
+	}{
+		{"", "file:///docs/api.md", false},
+		{"mcp://project/", "mcp://project/docs/api.md", false},
+		{"mcp://project", "mcp://project/docs/api.md", false},
+		{"https://example.com/kb", "https://example.com/kb/docs/api.md", false},
+		{"project/", "", true},
+		{"mcp://project/?version=2", "", true},
+		{"mcp://project/#docs", "", true},
+		{"://project", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			r := NewRegistry()
+			err := r.SetResourceURIs(dir, tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetResourceURIs(%q) error = %v, wantErr %v", tt.base, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if err := r.RegisterResource("docs/api", path); err != nil {
+				t.Fatal(err)
+			}
+			if resource, _ := r.GetResource("docs/api"); resource.URI != tt.wantURI {
+				t.Errorf("URI = %s, want %s", resource.URI, tt.wantURI)
+			}
+		})
+	}
+}
+
+func TestRegistry_OnResourceChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := NewRegistry()
+	var changes []string
+	reg.OnResourceChange(func(name string, resource *ResourceInfo) {
+		// The registry is usable from the callback
+		_, registered := reg.GetResource(name)
+		if (resource != nil) != registered {
+			t.Errorf("Resource %s passed as %v while registered = %v", name, resource, registered)
+		}
+		if resource == nil {
+			changes = append(changes, "removed "+name)
+		} else {
+			changes = append(changes, "updated "+name+" "+resource.Title)
+		}
+	})
+
+	if err := reg.RegisterResource("notes", path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+metadataExtension, []byte(`{"title": "Meeting notes"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reg.ReloadResourceMetadata("notes")
+	reg.UnregisterResource("notes")
+	reg.UnregisterResource("notes")
+
+	want := "updated notes |updated notes Meeting notes|removed notes"
+	if got := strings.Join(changes, "|"); got != want {
+		t.Errorf("Changes = %q, want %q", got, want)
+	}
+}
+
+func TestRegistry_BuiltinToolName(t *testing.T) {
+	reg := NewRegistry()
+	builtin := ToolFunc(func(ctx context.Context, input []byte) ([]byte, error) { return []byte(`{}`), nil })
+	if err := reg.RegisterToolInfo(&ToolInfo{Name: "search", Type: BuiltinTool, Handler: builtin}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither files nor functions can take over the name of a built-in tool
+	toolPath := filepath.Join(t.TempDir(), "search.py")
+	if err := os.WriteFile(toolPath, []byte("print('{}')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterTool("search", toolPath, "A Python tool"); err == nil {
+		t.Error("Expected RegisterTool() to reject the name of a built-in tool")
+	}
+	echo := func(ctx context.Context, args struct{}) (string, error) { return "", nil }
+	if err := reg.RegisterFunc("search", "A function tool", echo); err == nil {
+		t.Error("Expected RegisterFunc() to reject the name of a built-in tool")
+	}
+
+	// Nor does removing a file of the same name unregister it
+	reg.UnregisterToolFile("search", toolPath)
+	reg.UnregisterTool("search")
+	if tool, exists := reg.GetTool("search"); !exists || tool.Type != BuiltinTool {
+		t.Fatal("Expected the built-in tool to stay registered")
+	}
+}
@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// metadataExtension is appended to the file name of a resource to form its metadata sidecar
+const metadataExtension = ".meta.json"
+
+// maxFrontMatterBytes bounds how much of a Markdown file is searched for front matter
+const maxFrontMatterBytes = 64 << 10
+
+// ResourceMetadata describes a resource for clients, loaded from the front
+// matter of Markdown resources and from <file>.meta.json sidecars
+type ResourceMetadata struct {
+	Title       string               `yaml:"title" json:"title,omitempty"`
+	Description string               `yaml:"description" json:"description,omitempty"`
+	Annotations *ResourceAnnotations `yaml:"annotations" json:"annotations,omitempty"`
+}
+
+// ResourceAnnotations are the MCP annotations of a resource
+type ResourceAnnotations struct {
+	Audience     []string `yaml:"audience" json:"audience,omitempty"`         // "user" and/or "assistant"
+	Priority     *float64 `yaml:"priority" json:"priority,omitempty"`         // Importance from 0 to 1
+	LastModified string   `yaml:"lastModified" json:"lastModified,omitempty"` // RFC 3339, defaults to the file modification time
+}
+
+// IsMetadataSidecar reports whether a file holds the metadata of another resource file
+func IsMetadataSidecar(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), metadataExtension)
+}
+
+// MetadataSubject returns the path of the resource file a metadata sidecar describes
+func MetadataSubject(sidecarPath string) string {
+	return sidecarPath[:len(sidecarPath)-len(metadataExtension)]
+}
+
+// ReloadResourceMetadata re-reads the metadata of a resource after its sidecar changed
+func (r *Registry) ReloadResourceMetadata(name string) {
+	r.mutex.Lock()
+	resource, exists := r.resources[name]
+	if !exists {
+		r.mutex.Unlock()
+		return
+	}
+
+	// Replace the resource rather than mutating it, since readers hold no lock
+	updated := *resource
+	applyResourceMetadata(&updated)
+	r.resources[name] = &updated
+	r.mutex.Unlock()
+
+	r.notifyResourceChange(name, &updated)
+}
+
+// applyResourceMetadata sets the size and the descriptive fields of a
+// resource. Invalid metadata is logged and left out rather than failing the
+// registration.
+func applyResourceMetadata(resourceInfo *ResourceInfo) {
+	resourceInfo.Title, resourceInfo.Size, resourceInfo.Annotations = "", 0, nil
+	if resourceInfo.API != nil {
+		resourceInfo.Description = resourceInfo.API.Description
+	} else {
+		resourceInfo.Description = ""
+	}
+
+	var modified time.Time
+	if info, err := os.Stat(resourceInfo.FilePath); err == nil {
+		modified = info.ModTime()
+		// The definition file of an API resource says nothing about the size of its content
+		if resourceInfo.Type != APIRResource {
+			resourceInfo.Size = info.Size()
+		}
+	}
+
+	metadata, err := loadResourceMetadata(resourceInfo.FilePath, resourceInfo.MimeType)
+	if err != nil {
+		log.Printf("⚠️  Ignoring metadata of resource %s: %v", resourceInfo.Name, err)
+		metadata = &ResourceMetadata{}
+	}
+
+	resourceInfo.Title = metadata.Title
+	if metadata.Description != "" {
+		resourceInfo.Description = metadata.Description
+	}
+	resourceInfo.Annotations = metadata.Annotations
+	if !modified.IsZero() {
+		if resourceInfo.Annotations == nil {
+			resourceInfo.Annotations = &ResourceAnnotations{}
+		}
+		if resourceInfo.Annotations.LastModified == "" {
+			resourceInfo.Annotations.LastModified = modified.UTC().Format(time.RFC3339)
+		}
+	}
+}
+
+// loadResourceMetadata reads the front matter of a Markdown resource and the
+// sidecar of any resource. Fields set in the sidecar take precedence.
+func loadResourceMetadata(filePath, mimeType string) (*ResourceMetadata, error) {
+	metadata := &ResourceMetadata{}
+
+	if mimeType == "text/markdown" {
+		frontMatter, err := readFrontMatter(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if frontMatter != nil {
+			if err := yaml.Unmarshal(frontMatter, metadata); err != nil {
+				return nil, fmt.Errorf("failed to parse front matter of %s: %w", filePath, err)
+			}
+		}
+	}
+
+	sidecarPath := filePath + metadataExtension
+	content, err := os.ReadFile(sidecarPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read metadata %s: %w", sidecarPath, err)
+	}
+	if err == nil {
+		var sidecar ResourceMetadata
+		// JSON is YAML, decoding it as such shares the field tags with front matter
+		if err := yaml.Unmarshal(content, &sidecar); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata %s: %w", sidecarPath, err)
+		}
+		metadata.merge(&sidecar)
+	}
+
+	if err := metadata.validate(); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// merge overrides fields with the ones set in override
+func (m *ResourceMetadata) merge(override *ResourceMetadata) {
+	if override.Title != "" {
+		m.Title = override.Title
+	}
+	if override.Description != "" {
+		m.Description = override.Description
+	}
+	if override.Annotations == nil {
+		return
+	}
+	if m.Annotations == nil {
+		m.Annotations = &ResourceAnnotations{}
+	}
+	if override.Annotations.Audience != nil {
+		m.Annotations.Audience = override.Annotations.Audience
+	}
+	if override.Annotations.Priority != nil {
+		m.Annotations.Priority = override.Annotations.Priority
+	}
+	if override.Annotations.LastModified != "" {
+		m.Annotations.LastModified = override.Annotations.LastModified
+	}
+}
+
+// validate checks the annotations against the values MCP allows
+func (m *ResourceMetadata) validate() error {
+	if m.Annotations == nil {
+		return nil
+	}
+	for _, audience := range m.Annotations.Audience {
+		if audience != "user" && audience != "assistant" {
+			return fmt.Errorf("audience must be user or assistant, got %q", audience)
+		}
+	}
+	if priority := m.Annotations.Priority; priority != nil && (*priority < 0 || *priority > 1) {
+		return fmt.Errorf("priority must be between 0 and 1, got %v", *priority)
+	}
+	if lastModified := m.Annotations.LastModified; lastModified != "" {
+		if _, err := time.Parse(time.RFC3339, lastModified); err != nil {
+			return fmt.Errorf("lastModified must be an RFC 3339 timestamp, got %q", lastModified)
+		}
+	}
+	return nil
+}
+
+// readFrontMatter returns the YAML front matter at the start of a Markdown
+// file, delimited by --- lines, or nil if the file has none
+func readFrontMatter(filePath string) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(io.LimitReader(file, maxFrontMatterBytes))
+	first, err := reader.ReadString('\n')
+	if strings.TrimRight(first, "\r\n") != "---" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, nil
+	}
+
+	var frontMatter bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if trimmed := strings.TrimRight(line, "\r\n"); trimmed == "---" || trimmed == "..." {
+			return frontMatter.Bytes(), nil
+		}
+		frontMatter.WriteString(line)
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("front matter of %s is not closed within %d bytes", filePath, maxFrontMatterBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistry_ResourceMetadata(t *testing.T) {
+	frontMatter := "---\ntitle: API Guide\ndescription: How to call the API\nannotations:\n  audience: [assistant]\n  priority: 0.8\n---\n# API\n"
+
+	tests := []struct {
+		name            string
+		file            string
+		content         string
+		sidecar         string
+		wantTitle       string
+		wantDescription string
+		wantAudience    []string
+		wantPriority    float64
+		wantModified    string
+	}{
+		{"front matter", "guide.md", frontMatter, "", "API Guide", "How to call the API", []string{"assistant"}, 0.8, ""},
+		{"sidecar overrides front matter", "guide.md", frontMatter, `{"title": "Guide", "annotations": {"priority": 0.2, "lastModified": "2025-01-02T03:04:05Z"}}`,
+			"Guide", "How to call the API", []string{"assistant"}, 0.2, "2025-01-02T03:04:05Z"},
+		{"sidecar of any file", "sales.csv", "region,total\n", `{"description": "Sales by region", "annotations": {"audience": ["user"]}}`, "", "Sales by region", []string{"user"}, 0, ""},
+		{"front matter only read from markdown", "notes.txt", frontMatter, "", "", "", nil, 0, ""},
+		{"invalid metadata is ignored", "guide.md", frontMatter, `{"annotations": {"priority": 3}}`, "", "", nil, 0, ""},
+		{"unclosed front matter is ignored", "rule.md", "---\nnot front matter\n", "", "", "", nil, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.sidecar != "" {
+				if err := os.WriteFile(path+".meta.json", []byte(tt.sidecar), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reg := NewRegistry()
+			if err := reg.RegisterResource("resource", path); err != nil {
+				t.Fatal(err)
+			}
+			resource, _ := reg.GetResource("resource")
+
+			if resource.Title != tt.wantTitle || resource.Description != tt.wantDescription {
+				t.Errorf("title, description = %q, %q, want %q, %q", resource.Title, resource.Description, tt.wantTitle, tt.wantDescription)
+			}
+			if resource.Size != int64(len(tt.content)) {
+				t.Errorf("size = %d, want %d", resource.Size, len(tt.content))
+			}
+
+			annotations := resource.Annotations
+			if annotations == nil {
+				t.Fatal("Expected annotations with the modification time")
+			}
+			if len(annotations.Audience) != len(tt.wantAudience) || (len(tt.wantAudience) > 0 && annotations.Audience[0] != tt.wantAudience[0]) {
+				t.Errorf("audience = %v, want %v", annotations.Audience, tt.wantAudience)
+			}
+			if priority := annotations.Priority; (priority == nil) != (tt.wantPriority == 0) || (priority != nil && *priority != tt.wantPriority) {
+				t.Errorf("priority = %v, want %v", priority, tt.wantPriority)
+			}
+			if tt.wantModified != "" && annotations.LastModified != tt.wantModified {
+				t.Errorf("lastModified = %s, want %s", annotations.LastModified, tt.wantModified)
+			}
+			if _, err := time.Parse(time.RFC3339, annotations.LastModified); err != nil {
+				t.Errorf("lastModified = %q is not RFC 3339", annotations.LastModified)
+			}
+		})
+	}
+}
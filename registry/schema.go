@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	rawMessageType     = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// SchemaForType derives a JSON Schema from a Go type. Struct fields use their
+// json names and honor the following tags:
+//
+//	description:"Text shown to the client"
+//	enum:"a,b,c"
+//	required:"true" or required:"false" (default: required unless omitempty or a pointer)
+func SchemaForType(t reflect.Type) map[string]interface{} {
+	return schemaForType(t, map[reflect.Type]bool{})
+}
+
+// schemaForType derives a schema while guarding against recursive types
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType || t == emptyInterfaceType:
+		return map[string]interface{}{}
+	case t.Kind() != reflect.Struct && t.Implements(jsonMarshalerType):
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaForType(t.Elem(), visiting),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaForType(t.Elem(), visiting),
+		}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		addStructFields(t, properties, &required, visiting)
+
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// addStructFields adds the schema of every exported field, flattening embedded
+// structs the same way encoding/json does
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		tagName, options, _ := strings.Cut(jsonTag, ",")
+
+		fieldType := field.Type
+		if field.Anonymous && tagName == "" {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				addStructFields(fieldType, properties, required, visiting)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tagName != "" {
+			name = tagName
+		}
+
+		property := schemaForType(field.Type, visiting)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			// The enum of a list field applies to its items
+			if items, ok := property["items"].(map[string]interface{}); ok {
+				items["enum"] = enumValues(elemType(field.Type), enum)
+			} else {
+				property["enum"] = enumValues(field.Type, enum)
+			}
+		}
+		properties[name] = property
+
+		isRequired := !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Ptr
+		if tag := field.Tag.Get("required"); tag != "" {
+			isRequired = tag == "true"
+		}
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// elemType returns the element type of a slice or array, behind any pointers
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Elem()
+}
+
+// enumValues converts a comma-separated enum tag to values of the field's JSON type
+func enumValues(t reflect.Type, enum string) []interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	parts := strings.Split(enum, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value, err := strconv.ParseInt(part, 10, 64); err == nil {
+				values = append(values, value)
+				continue
+			}
+		case reflect.Float32, reflect.Float64:
+			if value, err := strconv.ParseFloat(part, 64); err == nil {
+				values = append(values, value)
+				continue
+			}
+		case reflect.Bool:
+			if value, err := strconv.ParseBool(part); err == nil {
+				values = append(values, value)
+				continue
+			}
+		}
+		values = append(values, part)
+	}
+	return values
+}
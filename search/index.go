@@ -0,0 +1,308 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"gin-mcp/registry"
+)
+
+// BM25 parameters: term frequency saturation and document length normalization
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// MaxDocumentBytes bounds how much of a resource file is indexed
+const MaxDocumentBytes = 1 << 20
+
+// maxTermBytes leaves out long runs of letters and digits such as hashes and encoded data
+const maxTermBytes = 64
+
+// snippetContext is the number of bytes shown around the first match in a snippet
+const snippetContext = 80
+
+// Index is an in-memory inverted index of text resources, ranked with BM25.
+// Resources are identified by name, adding a resource again replaces it.
+type Index struct {
+	documents   map[string]*document      // By resource name
+	postings    map[string]map[string]int // Term frequencies by term and resource name
+	totalLength int
+	mutex       sync.RWMutex
+}
+
+// document is an indexed resource
+type document struct {
+	resource *registry.ResourceInfo
+	content  string         // Indexed content, kept for snippets
+	terms    map[string]int // Term frequencies
+	length   int            // Number of terms
+}
+
+// Result is a resource matching a search, with the passage of its content
+// around the first match
+type Result struct {
+	Name        string  `json:"name"`
+	URI         string  `json:"uri"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	MimeType    string  `json:"mime_type"`
+	Score       float64 `json:"score"`
+	Snippet     string  `json:"snippet,omitempty"`
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		documents: make(map[string]*document),
+		postings:  make(map[string]map[string]int),
+	}
+}
+
+// Add indexes the title, description and content of a resource, replacing
+// what was indexed under its name. Only the first MaxDocumentBytes of a file
+// are indexed. Resources without text content, such as images and remote API
+// resources, are indexed by title and description only.
+func (i *Index) Add(resource *registry.ResourceInfo) error {
+	var content string
+	if resource.Type != registry.APIRResource && registry.IsTextMimeType(resource.MimeType) {
+		var err error
+		if content, err = readContent(resource.FilePath); err != nil {
+			return fmt.Errorf("failed to index resource %s: %w", resource.Name, err)
+		}
+	}
+
+	doc := &document{
+		resource: resource,
+		content:  content,
+		terms:    make(map[string]int),
+	}
+	for _, text := range []string{resource.Title, resource.Description, content} {
+		tokenize(text, func(term string, start, end int) bool {
+			doc.terms[term]++
+			doc.length++
+			return true
+		})
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.remove(resource.Name)
+	i.documents[resource.Name] = doc
+	i.totalLength += doc.length
+	for term, frequency := range doc.terms {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[string]int)
+		}
+		i.postings[term][resource.Name] = frequency
+	}
+	return nil
+}
+
+// Remove drops a resource from the index
+func (i *Index) Remove(name string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.remove(name)
+}
+
+// remove drops a resource from the index, the caller holds the lock
+func (i *Index) remove(name string) {
+	doc, exists := i.documents[name]
+	if !exists {
+		return
+	}
+
+	for term := range doc.terms {
+		delete(i.postings[term], name)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	i.totalLength -= doc.length
+	delete(i.documents, name)
+}
+
+// Count returns the number of indexed resources
+func (i *Index) Count() int {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	return len(i.documents)
+}
+
+// Search returns up to limit resources matching any word of the query, best
+// match first. A limit below 1 returns all matches.
+func (i *Index) Search(query string, limit int) []Result {
+	terms := make(map[string]bool)
+	tokenize(query, func(term string, start, end int) bool {
+		terms[term] = true
+		return true
+	})
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if len(terms) == 0 || len(i.documents) == 0 {
+		return []Result{}
+	}
+
+	count := float64(len(i.documents))
+	averageLength := float64(i.totalLength) / count
+
+	scores := make(map[string]float64)
+	for term := range terms {
+		postings := i.postings[term]
+		frequency := float64(len(postings))
+		idf := math.Log(1 + (count-frequency+0.5)/(frequency+0.5))
+
+		for name, termFrequency := range postings {
+			tf := float64(termFrequency)
+			norm := 1 - b + b*float64(i.documents[name].length)/averageLength
+			scores[name] += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+	}
+
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(x, y int) bool {
+		if scores[names[x]] != scores[names[y]] {
+			return scores[names[x]] > scores[names[y]]
+		}
+		return names[x] < names[y]
+	})
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+
+	results := make([]Result, len(names))
+	for n, name := range names {
+		doc := i.documents[name]
+		text := doc.content
+		if text == "" {
+			text = doc.resource.Description
+		}
+		results[n] = Result{
+			Name:        name,
+			URI:         doc.resource.URI,
+			Title:       doc.resource.Title,
+			Description: doc.resource.Description,
+			MimeType:    doc.resource.MimeType,
+			Score:       math.Round(scores[name]*1000) / 1000,
+			Snippet:     snippet(text, terms),
+		}
+	}
+	return results
+}
+
+// readContent reads the indexed part of a resource file, or "" if it is not UTF-8 text
+func readContent(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxDocumentBytes))
+	if err != nil {
+		return "", err
+	}
+
+	// A file cut at the limit may end inside a character
+	if len(data) == MaxDocumentBytes {
+		for cut := 1; cut < utf8.UTFMax && !utf8.Valid(data); cut++ {
+			data = data[:len(data)-1]
+		}
+	}
+	if !utf8.Valid(data) {
+		return "", nil
+	}
+	return string(data), nil
+}
+
+// tokenize calls fn with the lowercase words of letters and digits in text and
+// their byte positions, until fn returns false
+func tokenize(text string, fn func(term string, start, end int) bool) {
+	start := -1
+	for position, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = position
+			}
+			continue
+		}
+		if start >= 0 && !emit(text, start, position, fn) {
+			return
+		}
+		start = -1
+	}
+	if start >= 0 {
+		emit(text, start, len(text), fn)
+	}
+}
+
+// emit passes a word to the tokenize callback unless it is too long to be a term
+func emit(text string, start, end int, fn func(term string, start, end int) bool) bool {
+	if end-start > maxTermBytes {
+		return true
+	}
+	return fn(strings.ToLower(text[start:end]), start, end)
+}
+
+// snippet returns the passage of text around the first occurrence of a term,
+// with whitespace collapsed and ellipses where the text was cut. Without an
+// occurrence the passage is taken from the start.
+func snippet(text string, terms map[string]bool) string {
+	start, end := 0, 0
+	tokenize(text, func(term string, termStart, termEnd int) bool {
+		if terms[term] {
+			start, end = termStart, termEnd
+			return false
+		}
+		return true
+	})
+
+	from := max(start-snippetContext, 0)
+	to := min(end+2*snippetContext, len(text))
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	// Cut at whitespace rather than inside words
+	if from > 0 {
+		if space := strings.IndexFunc(text[from:start], unicode.IsSpace); space >= 0 {
+			from += space
+		}
+	}
+	if to < len(text) {
+		if space := strings.LastIndexFunc(text[end:to], unicode.IsSpace); space >= 0 {
+			to = end + space
+		}
+	}
+
+	passage := strings.Join(strings.Fields(text[from:to]), " ")
+	if passage == "" {
+		return ""
+	}
+	if from > 0 {
+		passage = "…" + passage
+	}
+	if to < len(text) {
+		passage += "…"
+	}
+	return passage
+}
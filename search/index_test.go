@@ -0,0 +1,104 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin-mcp/registry"
+)
+
+func TestIndex_Search(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"deploy.md":  "# Deploying\n\nDeploy the service with the deploy script, then check the deploy log.",
+		"auth.md":    "# Authentication\n\nTokens are issued by the auth service and expire after one hour.",
+		"logging.md": "# Logging\n\nEvery service writes a structured log. " + strings.Repeat("Padding text. ", 40) + "Rotate the log daily.",
+		"logo.png":   "\x89PNG\r\n\x1a\nlog",
+	}
+
+	index := NewIndex()
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mimeType := "text/markdown"
+		if strings.HasSuffix(file, ".png") {
+			mimeType = "image/png"
+		}
+		name := strings.TrimSuffix(file, filepath.Ext(file))
+		resource := &registry.ResourceInfo{Name: name, URI: "file:///" + file, FilePath: path, MimeType: mimeType}
+		if name == "auth" {
+			resource.Title = "Login tokens"
+		}
+		if err := index.Add(resource); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		limit       int
+		wantNames   []string
+		wantSnippet string
+	}{
+		{"term frequency ranks first", "deploy log", 0, []string{"deploy", "logging"}, "# Deploying Deploy the service with the deploy script, then check the deploy log."},
+		{"case insensitive", "TOKENS", 1, []string{"auth"}, ""},
+		{"title", "login", 0, []string{"auth"}, "# Authentication Tokens are issued by the auth service and expire after one hour."},
+		{"snippet around a late match", "rotate", 0, []string{"logging"}, "…text. Padding text. Padding text. Padding text. Padding text. Padding text. Rotate the log daily."},
+		{"binary content is not indexed", "png", 0, []string{}, ""},
+		{"no match", "kubernetes", 0, []string{}, ""},
+		{"no words", "?!", 0, []string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := index.Search(tt.query, tt.limit)
+
+			names := make([]string, len(results))
+			for i, result := range results {
+				names[i] = result.Name
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Fatalf("Results = %v, want %v", names, tt.wantNames)
+			}
+			if tt.wantSnippet != "" && results[0].Snippet != tt.wantSnippet {
+				t.Errorf("Snippet = %q, want %q", results[0].Snippet, tt.wantSnippet)
+			}
+		})
+	}
+}
+
+func TestIndex_AddReplacesAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	resource := &registry.ResourceInfo{Name: "notes", FilePath: path, MimeType: "text/plain"}
+
+	index := NewIndex()
+	if err := os.WriteFile(path, []byte("alpha beta"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Add(resource); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("gamma"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Add(resource); err != nil {
+		t.Fatal(err)
+	}
+	if results := index.Search("alpha", 0); len(results) != 0 {
+		t.Errorf("Expected the previous content to be dropped, got %v", results)
+	}
+	if results := index.Search("gamma", 0); len(results) != 1 {
+		t.Errorf("Expected the new content to match, got %v", results)
+	}
+
+	index.Remove("notes")
+	if index.Count() != 0 || len(index.postings) != 0 || index.totalLength != 0 {
+		t.Errorf("Expected an empty index, got %d documents and %d terms", index.Count(), len(index.postings))
+	}
+}
@@ -2,6 +2,7 @@ package watcher
 
 import (
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,12 +13,13 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
-// Watcher monitors file system changes for MCP resources and tools
+// Watcher monitors file system changes for MCP resources, tools and models
 type Watcher struct {
 	watcher      *fsnotify.Watcher
 	registry     *registry.Registry
 	resourcesDir string
 	toolsDir     string
+	modelsDir    string
 	isRunning    bool
 	stopChan     chan bool
 }
@@ -32,12 +34,20 @@ func NewWatcher(resourcesDir, toolsDir string, registry *registry.Registry) (*Wa
 	return &Watcher{
 		watcher:      watcher,
 		registry:     registry,
-		resourcesDir: resourcesDir,
-		toolsDir:     toolsDir,
+		resourcesDir: filepath.Clean(resourcesDir),
+		toolsDir:     filepath.Clean(toolsDir),
 		stopChan:     make(chan bool),
 	}, nil
 }
 
+// SetModelsDir enables watching of a models directory. It must be called before Start.
+func (w *Watcher) SetModelsDir(modelsDir string) {
+	if modelsDir != "" {
+		modelsDir = filepath.Clean(modelsDir)
+	}
+	w.modelsDir = modelsDir
+}
+
 // Start begins watching for file changes
 func (w *Watcher) Start() error {
 	// Create directories if they don't exist
@@ -45,15 +55,21 @@ func (w *Watcher) Start() error {
 		return fmt.Errorf("failed to ensure directories: %w", err)
 	}
 
-	// Add directories to watcher
-	if err := w.watcher.Add(w.resourcesDir); err != nil {
+	// Add directories and their subdirectories to watcher
+	if err := w.watchTree(w.resourcesDir); err != nil {
 		return fmt.Errorf("failed to add resources directory to watcher: %w", err)
 	}
 
-	if err := w.watcher.Add(w.toolsDir); err != nil {
+	if err := w.watchTree(w.toolsDir); err != nil {
 		return fmt.Errorf("failed to add tools directory to watcher: %w", err)
 	}
 
+	if w.modelsDir != "" {
+		if err := w.watchTree(w.modelsDir); err != nil {
+			return fmt.Errorf("failed to add models directory to watcher: %w", err)
+		}
+	}
+
 	// Initial scan of existing files
 	if err := w.scanExistingFiles(); err != nil {
 		return fmt.Errorf("failed to scan existing files: %w", err)
@@ -90,9 +106,12 @@ func (w *Watcher) IsRunning() bool {
 	return w.isRunning
 }
 
-// ensureDirectories creates the resources and tools directories if they don't exist
+// ensureDirectories creates the watched directories if they don't exist
 func (w *Watcher) ensureDirectories() error {
 	dirs := []string{w.resourcesDir, w.toolsDir}
+	if w.modelsDir != "" {
+		dirs = append(dirs, w.modelsDir)
+	}
 
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -106,46 +125,141 @@ func (w *Watcher) ensureDirectories() error {
 // scanExistingFiles scans for existing files and registers them
 func (w *Watcher) scanExistingFiles() error {
 	// Scan resources directory
-	if err := w.scanDirectory(w.resourcesDir, "resource"); err != nil {
+	if err := w.scanDirectory(w.resourcesDir, w.resourcesDir, "resource"); err != nil {
 		return fmt.Errorf("failed to scan resources directory: %w", err)
 	}
 
 	// Scan tools directory
-	if err := w.scanDirectory(w.toolsDir, "tool"); err != nil {
+	if err := w.scanDirectory(w.toolsDir, w.toolsDir, "tool"); err != nil {
 		return fmt.Errorf("failed to scan tools directory: %w", err)
 	}
 
+	// Scan models directory
+	if w.modelsDir != "" {
+		if err := w.scanDirectory(w.modelsDir, w.modelsDir, "model"); err != nil {
+			return fmt.Errorf("failed to scan models directory: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// scanDirectory scans a directory for files and registers them
-func (w *Watcher) scanDirectory(dir, itemType string) error {
-	entries, err := os.ReadDir(dir)
+// scanDirectory scans a directory and its subdirectories for files and
+// registers them, named by their path relative to root
+func (w *Watcher) scanDirectory(root, dir, itemType string) error {
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != dir && skipDir(filePath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		w.registerFile(root, filePath, itemType)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to read directory %s: %w", dir, err)
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	return nil
+}
+
+// registerFile registers a file found by a scan
+func (w *Watcher) registerFile(root, filePath, itemType string) {
+	name := itemName(root, filePath, itemType)
+
+	if itemType == "resource" {
+		// Metadata sidecars are loaded together with their resource
+		if registry.IsMetadataSidecar(filePath) {
+			return
+		}
+		if err := w.registry.RegisterResource(name, filePath); err != nil {
+			log.Printf("⚠️  Failed to register resource %s: %v", name, err)
+		}
+	} else if itemType == "tool" {
+		// Manifests and requirements are loaded together with their tool
+		if registry.IsManifest(filePath) || registry.IsRequirements(filePath) {
+			return
+		}
+		description := fmt.Sprintf("MCP tool: %s", name)
+		if err := w.registry.RegisterTool(name, filePath, description); err != nil {
+			log.Printf("⚠️  Failed to register tool %s: %v", name, err)
 		}
+	} else if itemType == "model" {
+		if err := w.registry.RegisterModel(name, filePath); err != nil {
+			log.Printf("⚠️  Failed to register model %s: %v", name, err)
+		}
+	}
+}
 
-		filePath := filepath.Join(dir, entry.Name())
-		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+// itemName derives the registry name of a file. Resources keep the slashes of
+// their relative path, tools and models cannot.
+func itemName(root, filePath, itemType string) string {
+	if itemType == "resource" {
+		return registry.RelativeName(root, filePath)
+	}
+	return registry.RelativeToolName(root, filePath)
+}
 
-		if itemType == "resource" {
-			if err := w.registry.RegisterResource(name, filePath); err != nil {
-				log.Printf("⚠️  Failed to register resource %s: %v", name, err)
+// watchTree adds a directory and its subdirectories to the watcher
+func (w *Watcher) watchTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != dir && skipDir(path) {
+			return filepath.SkipDir
+		}
+		return w.watcher.Add(path)
+	})
+}
+
+// unregisterTree unregisters the items of a removed or renamed directory
+func (w *Watcher) unregisterTree(dir, itemType string) {
+	switch itemType {
+	case "resource":
+		for _, resource := range w.registry.ListResources() {
+			if within(dir, resource.FilePath) {
+				w.registry.UnregisterResource(resource.Name)
 			}
-		} else if itemType == "tool" {
-			description := fmt.Sprintf("MCP tool: %s", name)
-			if err := w.registry.RegisterTool(name, filePath, description); err != nil {
-				log.Printf("⚠️  Failed to register tool %s: %v", name, err)
+		}
+	case "tool":
+		for _, tool := range w.registry.ListTools() {
+			if tool.FilePath != "" && tool.Type != registry.ModelTool && within(dir, tool.FilePath) {
+				w.registry.UnregisterToolFile(tool.Name, tool.FilePath)
+			}
+		}
+	case "model":
+		for _, model := range w.registry.ListModels() {
+			if within(dir, model.FilePath) {
+				w.registry.UnregisterModel(model.Name)
 			}
 		}
 	}
+}
 
-	return nil
+// skipDir reports whether a subdirectory is left out of scanning and watching:
+// hidden directories, Python bytecode caches and virtualenvs
+func skipDir(dir string) bool {
+	base := filepath.Base(dir)
+	if strings.HasPrefix(base, ".") || base == "__pycache__" {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "pyvenv.cfg"))
+	return err == nil
+}
+
+// within reports whether a path lies below a directory
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 // watchLoop monitors for file system events
@@ -177,15 +291,68 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		return
 	}
 
-	// Determine if this is a resource or tool based on the directory
-	isResource := strings.HasPrefix(event.Name, w.resourcesDir)
-	isTool := strings.HasPrefix(event.Name, w.toolsDir)
+	// Determine if this is a resource, tool or model based on the directory
+	isResource := within(w.resourcesDir, event.Name)
+	isTool := within(w.toolsDir, event.Name)
+	isModel := w.modelsDir != "" && within(w.modelsDir, event.Name)
+
+	var root, itemType string
+	switch {
+	case isResource:
+		root, itemType = w.resourcesDir, "resource"
+	case isTool:
+		root, itemType = w.toolsDir, "tool"
+	case isModel:
+		root, itemType = w.modelsDir, "model"
+	default:
+		return
+	}
+
+	name := itemName(root, event.Name, itemType)
+
+	// A new subdirectory is watched and scanned, since files may have been
+	// created in it before the watch was added
+	if event.Op == fsnotify.Create {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if skipDir(event.Name) {
+				return
+			}
+			if err := w.watchTree(event.Name); err != nil {
+				log.Printf("⚠️  Failed to watch directory %s: %v", event.Name, err)
+			}
+			if err := w.scanDirectory(root, event.Name, itemType); err != nil {
+				log.Printf("⚠️  Failed to scan directory %s: %v", event.Name, err)
+			}
+			log.Printf("📂 Watching new directory %s", event.Name)
+			return
+		}
+	}
+
+	// A removed or renamed directory takes its items with it
+	if event.Op == fsnotify.Remove || event.Op == fsnotify.Rename {
+		w.unregisterTree(event.Name, itemType)
+	}
 
-	if !isResource && !isTool {
+	// A changed metadata sidecar updates the title, description and annotations of its resource
+	if isResource && registry.IsMetadataSidecar(event.Name) {
+		subject := registry.RelativeName(root, registry.MetadataSubject(event.Name))
+		w.registry.ReloadResourceMetadata(subject)
 		return
 	}
 
-	name := strings.TrimSuffix(filepath.Base(event.Name), filepath.Ext(event.Name))
+	// A changed manifest updates the settings of its tool
+	if isTool && registry.IsManifest(event.Name) {
+		if err := w.registry.ReloadManifest(name); err != nil {
+			log.Printf("⚠️  Failed to reload manifest of tool %s: %v", name, err)
+		}
+		return
+	}
+
+	// Changed requirements may point Python tools to another virtualenv
+	if isTool && registry.IsRequirements(event.Name) {
+		w.registry.ReloadPythonEnvs(filepath.Dir(event.Name))
+		return
+	}
 
 	switch event.Op {
 	case fsnotify.Create, fsnotify.Write:
@@ -202,6 +369,12 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 			} else {
 				log.Printf("✅ Tool %s registered/updated", name)
 			}
+		} else if isModel {
+			if err := w.registry.RegisterModel(name, event.Name); err != nil {
+				log.Printf("⚠️  Failed to register model %s: %v", name, err)
+			} else {
+				log.Printf("✅ Model %s registered/updated", name)
+			}
 		}
 
 	case fsnotify.Remove:
@@ -209,8 +382,11 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 			w.registry.UnregisterResource(name)
 			log.Printf("🗑️  Resource %s unregistered", name)
 		} else if isTool {
-			w.registry.UnregisterTool(name)
+			w.registry.UnregisterToolFile(name, event.Name)
 			log.Printf("🗑️  Tool %s unregistered", name)
+		} else if isModel {
+			w.registry.UnregisterModel(name)
+			log.Printf("🗑️  Model %s unregistered", name)
 		}
 
 	case fsnotify.Rename:
@@ -219,8 +395,11 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 			w.registry.UnregisterResource(name)
 			log.Printf("🔄 Resource %s renamed", name)
 		} else if isTool {
-			w.registry.UnregisterTool(name)
+			w.registry.UnregisterToolFile(name, event.Name)
 			log.Printf("🔄 Tool %s renamed", name)
+		} else if isModel {
+			w.registry.UnregisterModel(name)
+			log.Printf("🔄 Model %s renamed", name)
 		}
 	}
 }
@@ -231,7 +410,9 @@ func (w *Watcher) GetStats() map[string]interface{} {
 		"running":       w.isRunning,
 		"resources_dir": w.resourcesDir,
 		"tools_dir":     w.toolsDir,
+		"models_dir":    w.modelsDir,
 		"resources":     w.registry.GetResourceCount(),
 		"tools":         w.registry.GetToolCount(),
+		"models":        w.registry.GetModelCount(),
 	}
 }